@@ -0,0 +1,251 @@
+// Package ansi tokenizes ANSI CSI SGR ("Select Graphic Rendition") escape
+// sequences out of a script's captured output and translates them into
+// lipgloss.Style runs, so the console's output pane renders colors and
+// attributes predictably instead of writing raw escape codes and hoping the
+// host terminal (or lipgloss's own width math) copes with them.
+package ansi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Segment is one contiguous run of text sharing a single set of SGR
+// attributes. Bold/Dim/Italic/Underline/Reverse are tri-state only in the
+// sense that false means "not set by this run"; FG/BG hold a lipgloss.Color
+// value, or "" when the run never set one (so a caller rendering against a
+// stream-specific default color knows to leave it alone).
+type Segment struct {
+	Bold, Dim, Italic, Underline, Reverse bool
+	FG, BG                                string
+	Text                                  string
+}
+
+// Style builds this segment's absolute lipgloss.Style, with unset FG/BG
+// left at lipgloss's own default foreground/background.
+func (seg Segment) Style() lipgloss.Style {
+	return seg.applyTo(lipgloss.NewStyle())
+}
+
+// StyleOn builds this segment's lipgloss.Style starting from base, so a
+// segment that never set its own color inherits base's instead of falling
+// back to the terminal default (e.g. so stderr lines stay reddish even
+// where the script itself emitted no color codes).
+func (seg Segment) StyleOn(base lipgloss.Style) lipgloss.Style {
+	return seg.applyTo(base)
+}
+
+func (seg Segment) applyTo(style lipgloss.Style) lipgloss.Style {
+	if seg.Bold {
+		style = style.Bold(true)
+	}
+	if seg.Dim {
+		style = style.Faint(true)
+	}
+	if seg.Italic {
+		style = style.Italic(true)
+	}
+	if seg.Underline {
+		style = style.Underline(true)
+	}
+	fg, bg := seg.FG, seg.BG
+	if seg.Reverse {
+		fg, bg = seg.BG, seg.FG
+		if fg == "" {
+			fg = "0"
+		}
+		if bg == "" {
+			bg = "15"
+		}
+	}
+	if fg != "" {
+		style = style.Foreground(lipgloss.Color(fg))
+	}
+	if bg != "" {
+		style = style.Background(lipgloss.Color(bg))
+	}
+	return style
+}
+
+// ansiColors are the 16 standard/bright named colors, indexed 0-7 (standard,
+// codes 30-37/40-47) and 8-15 (bright, codes 90-97/100-107).
+var ansiColors = [16]string{
+	"0", "1", "2", "3", "4", "5", "6", "7",
+	"8", "9", "10", "11", "12", "13", "14", "15",
+}
+
+// state tracks the SGR attributes in effect while scanning, snapshotted into
+// a Segment whenever a new text run starts.
+type state struct {
+	bold, dim, italic, underline, reverse bool
+	fg, bg                                string // lipgloss.Color value, or "" for unset/default
+}
+
+// Parse scans s for CSI SGR sequences ("\x1b[...m") and returns it as a
+// sequence of styled Segments with the escape codes themselves removed.
+// Malformed or incomplete sequences (a stray ESC with no matching 'm', or
+// parameters that don't parse as SGR codes) are dropped rather than passed
+// through literally, since a partial escape code rendered as text is worse
+// than losing a few characters of it.
+func Parse(s string) []Segment {
+	var segs []Segment
+	var cur state
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		segs = append(segs, Segment{
+			Bold: cur.bold, Dim: cur.dim, Italic: cur.italic, Underline: cur.underline, Reverse: cur.reverse,
+			FG: cur.fg, BG: cur.bg, Text: text.String(),
+		})
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\x1b' || i+1 >= len(s) || s[i+1] != '[' {
+			text.WriteByte(s[i])
+			continue
+		}
+
+		end := strings.IndexByte(s[i+2:], 'm')
+		if end < 0 {
+			// No terminating 'm' before the string ends: not a complete SGR
+			// sequence, so drop the ESC and every partial parameter byte
+			// after it instead of letting them fall through to text as
+			// literal output.
+			break
+		}
+		params := s[i+2 : i+2+end]
+		i += 2 + end // advance to the 'm' itself; the loop's i++ lands past it
+
+		flush()
+		applySGR(&cur, params)
+	}
+	flush()
+	return segs
+}
+
+// applySGR updates st in place for each semicolon-separated SGR parameter in
+// params, consuming the extra operands that 38/48 (extended color) need.
+func applySGR(st *state, params string) {
+	if params == "" {
+		params = "0"
+	}
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		n, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			*st = state{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.dim = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = true
+		case n == 7:
+			st.reverse = true
+		case n == 22:
+			st.bold, st.dim = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n == 27:
+			st.reverse = false
+		case n >= 30 && n <= 37:
+			st.fg = ansiColors[n-30]
+		case n == 38:
+			i += consumeExtendedColor(codes[i+1:], &st.fg)
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = ansiColors[n-40]
+		case n == 48:
+			i += consumeExtendedColor(codes[i+1:], &st.bg)
+		case n == 49:
+			st.bg = ""
+		case n >= 90 && n <= 97:
+			st.fg = ansiColors[8+n-90]
+		case n >= 100 && n <= 107:
+			st.bg = ansiColors[8+n-100]
+		default:
+			// Unsupported/rare SGR codes (51 framed, 53 overline, etc.) are
+			// silently stripped rather than passed through or mis-rendered.
+		}
+	}
+}
+
+// consumeExtendedColor parses the operands following a 38 or 48 code (either
+// "5;N" for a 256-color palette index or "2;R;G;B" for truecolor), sets
+// *target to the equivalent lipgloss.Color value, and returns how many of
+// rest's entries it consumed so the caller's loop can skip over them.
+func consumeExtendedColor(rest []string, target *string) int {
+	if len(rest) == 0 {
+		return 0
+	}
+	mode, err := strconv.Atoi(rest[0])
+	if err != nil {
+		return 0
+	}
+	switch mode {
+	case 5: // 256-color palette index
+		if len(rest) < 2 {
+			return 1
+		}
+		*target = rest[1]
+		return 2
+	case 2: // 24-bit truecolor
+		if len(rest) < 4 {
+			return len(rest)
+		}
+		r, rerr := strconv.Atoi(rest[1])
+		g, gerr := strconv.Atoi(rest[2])
+		b, berr := strconv.Atoi(rest[3])
+		if rerr != nil || gerr != nil || berr != nil {
+			return 4
+		}
+		*target = "#" + hex2(r) + hex2(g) + hex2(b)
+		return 4
+	default:
+		return 1
+	}
+}
+
+func hex2(n int) string {
+	const digits = "0123456789abcdef"
+	if n < 0 {
+		n = 0
+	}
+	if n > 255 {
+		n = 255
+	}
+	return string([]byte{digits[n/16], digits[n%16]})
+}
+
+// Render re-assembles Parse(s)'s segments back into a single string with
+// each run's style applied, for callers that just want styled output
+// without needing the Segment boundaries themselves.
+func Render(s string) string {
+	return RenderOn(s, lipgloss.NewStyle())
+}
+
+// RenderOn is Render, but base is used as the starting style for every
+// segment instead of lipgloss's bare default — so a line that never sets
+// its own foreground still inherits e.g. a stream-specific color.
+func RenderOn(s string, base lipgloss.Style) string {
+	var b strings.Builder
+	for _, seg := range Parse(s) {
+		b.WriteString(seg.StyleOn(base).Render(seg.Text))
+	}
+	return b.String()
+}
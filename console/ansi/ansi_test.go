@@ -0,0 +1,88 @@
+package ansi
+
+import "testing"
+
+func TestParsePlainTextIsOneSegment(t *testing.T) {
+	segs := Parse("hello world")
+	if len(segs) != 1 || segs[0].Text != "hello world" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestParseResetSplitsRuns(t *testing.T) {
+	segs := Parse("\x1b[1mbold\x1b[0mplain")
+	if len(segs) != 2 {
+		t.Fatalf("expected 2 segments, got %d: %+v", len(segs), segs)
+	}
+	if !segs[0].Bold || segs[0].Text != "bold" {
+		t.Errorf("unexpected first segment: %+v", segs[0])
+	}
+	if segs[1].Bold || segs[1].Text != "plain" {
+		t.Errorf("unexpected second segment: %+v", segs[1])
+	}
+}
+
+func TestParseStandardForegroundColor(t *testing.T) {
+	segs := Parse("\x1b[31mred\x1b[0m")
+	if len(segs) != 1 || segs[0].FG != "1" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestParseBrightBackgroundColor(t *testing.T) {
+	segs := Parse("\x1b[100mgray bg\x1b[0m")
+	if len(segs) != 1 || segs[0].BG != "8" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestParse256Color(t *testing.T) {
+	segs := Parse("\x1b[38;5;200mpink\x1b[0m")
+	if len(segs) != 1 || segs[0].FG != "200" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestParseTruecolor(t *testing.T) {
+	segs := Parse("\x1b[38;2;10;20;30mrgb\x1b[0m")
+	if len(segs) != 1 || segs[0].FG != "#0a141e" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestParseMultipleParamsInOneSequence(t *testing.T) {
+	segs := Parse("\x1b[1;4;31mstyled\x1b[0m")
+	if len(segs) != 1 {
+		t.Fatalf("expected 1 segment, got %d: %+v", len(segs), segs)
+	}
+	s := segs[0]
+	if !s.Bold || !s.Underline || s.FG != "1" {
+		t.Errorf("unexpected segment: %+v", s)
+	}
+}
+
+func TestParseUnsupportedCodeIsStripped(t *testing.T) {
+	segs := Parse("\x1b[51mframed\x1b[0m")
+	if len(segs) != 1 || segs[0].Text != "framed" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+	if segs[0].Bold || segs[0].FG != "" {
+		t.Errorf("expected no attributes set, got %+v", segs[0])
+	}
+}
+
+func TestParseIncompleteSequenceIsDropped(t *testing.T) {
+	segs := Parse("before\x1b[31")
+	if len(segs) != 1 || segs[0].Text != "before" {
+		t.Fatalf("unexpected segments: %+v", segs)
+	}
+}
+
+func TestRenderStripsEscapeCodes(t *testing.T) {
+	out := Render("\x1b[32mgreen\x1b[0m")
+	for i := 0; i < len(out); i++ {
+		if out[i] == '\x1b' {
+			t.Fatalf("rendered output still contains an escape byte: %q", out)
+		}
+	}
+}
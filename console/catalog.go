@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/openemr/openemr-on-eks/console/catalog"
+)
+
+// builtinCategories returns the console's compiled-in menu, used whenever no
+// commands.yaml is present next to the project root.
+func builtinCategories(scriptsPath string) []category {
+	return []category{
+		{
+			name: "Deployment",
+			icon: "🚀",
+			commands: []command{
+				{
+					title:       "Validate Prerequisites",
+					description: "Check required tools, AWS credentials, and deployment readiness",
+					script:      filepath.Join(scriptsPath, "validate-deployment.sh"),
+				},
+				{
+					title:       "Quick Deploy",
+					description: "Deploy infrastructure, OpenEMR, and monitoring stack in one command",
+					script:      filepath.Join(scriptsPath, "quick-deploy.sh"),
+				},
+				{
+					title:       "Check Deployment Health",
+					description: "Validate current deployment status and infrastructure health",
+					script:      filepath.Join(scriptsPath, "validate-deployment.sh"),
+				},
+				{
+					title:       "Deploy Training Setup",
+					description: "Deploy OpenEMR with synthetic patient data for training/testing",
+					script:      filepath.Join(scriptsPath, "deploy-training-openemr-setup.sh"),
+					args:        []string{"--use-default-dataset", "--max-records", "100"},
+				},
+			},
+		},
+		{
+			name: "Backup & Recovery",
+			icon: "💾",
+			commands: []command{
+				{
+					title:       "Backup Deployment",
+					description: "Create comprehensive backup of RDS, Kubernetes configs, and application data",
+					script:      filepath.Join(scriptsPath, "backup.sh"),
+				},
+				{
+					title:       "Restore Deployment",
+					description: "Restore RDS and application data from a backup snapshot",
+					script:      filepath.Join(scriptsPath, "restore.sh"),
+					prompts: []inputField{
+						{label: "Bucket", placeholder: "my-backup-bucket", required: true},
+						{label: "Snapshot", placeholder: "snap-123 (blank = latest)", required: false, emptyAdds: "--latest-snapshot"},
+					},
+				},
+			},
+		},
+		{
+			name: "Maintenance",
+			icon: "🔧",
+			commands: []command{
+				{
+					title:       "Check Component Versions",
+					description: "Check for available updates across all project components",
+					script:      filepath.Join(scriptsPath, "version-manager.sh"),
+					args:        []string{"check"},
+				},
+				{
+					title:       "Check OpenEMR Versions",
+					description: "Discover available OpenEMR Docker image versions from Docker Hub",
+					script:      filepath.Join(scriptsPath, "check-openemr-versions.sh"),
+				},
+				{
+					title:       "Search Codebase",
+					description: "Search for terms across the entire codebase (interactive)",
+					script:      filepath.Join(scriptsPath, "search-codebase.sh"),
+					prompts: []inputField{
+						{label: "Pattern", placeholder: "e.g. 7.0", required: false, flag: "search"},
+					},
+				},
+				{
+					title:       "Clean Deployment",
+					description: "Remove application layer while preserving infrastructure",
+					script:      filepath.Join(scriptsPath, "clean-deployment.sh"),
+					destructive: true,
+				},
+				{
+					title:       "Destroy Infrastructure",
+					description: "Completely destroy all infrastructure resources (use with caution)",
+					script:      filepath.Join(scriptsPath, "destroy.sh"),
+					destructive: true,
+				},
+			},
+		},
+	}
+}
+
+// catalogPathOverride is set from the --config flag, taking precedence over
+// catalog.Discover's default commands.yaml/.yml/.json search next to the
+// project root. Empty means "discover as usual".
+var catalogPathOverride string
+
+// resolveCatalogPath returns the catalog path to load for projectRoot,
+// honoring --config ahead of catalog.Discover's default search.
+func resolveCatalogPath(projectRoot string) (string, error) {
+	if catalogPathOverride != "" {
+		return catalogPathOverride, nil
+	}
+	return catalog.Discover(projectRoot)
+}
+
+// loadCategories prefers a catalog discovered at projectRoot (or pointed to
+// by --config), falling back to the built-in catalog when none is present or
+// the discovered file fails to load. A load failure is reported to stderr
+// rather than crashing the TUI, since a typo in a hand-edited catalog
+// shouldn't take down the whole console.
+func loadCategories(projectRoot, scriptsPath string) []category {
+	path, err := resolveCatalogPath(projectRoot)
+	if err != nil || path == "" {
+		return builtinCategories(scriptsPath)
+	}
+
+	f, err := catalog.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Ignoring invalid catalog %s: %v\n", path, err)
+		return builtinCategories(scriptsPath)
+	}
+
+	return fromCatalog(f.Enabled(projectRoot))
+}
+
+// runValidateCatalog implements `--validate-catalog`: it discovers and loads
+// commands.yaml exactly as the TUI would at startup, printing either a
+// success summary or the validation error, and returns the process exit
+// code.
+func runValidateCatalog() int {
+	root := os.Getenv("OPENEMR_EKS_PROJECT_ROOT")
+	if root == "" {
+		root = embeddedProjectRoot
+	}
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	path, err := resolveCatalogPath(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if path == "" {
+		fmt.Printf("No commands.yaml found under %s; the built-in catalog will be used.\n", root)
+		return 0
+	}
+
+	f, err := catalog.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	total := 0
+	for _, c := range f.Categories {
+		total += len(c.Commands)
+	}
+	fmt.Printf("✅ %s is valid: %d categories, %d commands\n", path, len(f.Categories), total)
+	return 0
+}
+
+// fromCatalog converts loaded catalog categories into the console's native
+// category/command/inputField types.
+func fromCatalog(cats []catalog.Category) []category {
+	out := make([]category, 0, len(cats))
+	for _, c := range cats {
+		cat := category{name: c.Name, icon: c.Icon}
+		for _, cc := range c.Commands {
+			cmd := command{
+				title:       cc.Title,
+				description: cc.Description,
+				script:      cc.Script,
+				args:        cc.Args,
+				destructive: cc.Destructive,
+				env:         cc.Env,
+				tags:        cc.Tags,
+				execMode:    parseExecMode(cc.Mode),
+			}
+			for _, p := range cc.Prompts {
+				// Validity of Type/Validate/Choices was already checked by
+				// catalog.Validate at load time, so the error here is ignored.
+				validator, _ := promptValidator(p)
+				cmd.prompts = append(cmd.prompts, inputField{
+					label:       p.Label,
+					placeholder: p.Placeholder,
+					required:    p.Required,
+					flag:        p.Flag,
+					emptyAdds:   p.EmptyAdds,
+					sensitive:   p.Sensitive,
+					validator:   validator,
+				})
+			}
+			cat.commands = append(cat.commands, cmd)
+		}
+		out = append(out, cat)
+	}
+	return out
+}
+
+// promptValidator builds the fieldValidator described by p.Type, if any.
+// p.Type selects the kind: "regex" (pattern in p.Validate), "enum" (options
+// in p.Choices), "range" (a "min:max" spec in p.Validate), or "path" (p.Validate
+// names "file", "dir", or "writable"; defaults to "file"). An empty Type
+// means no validator beyond the existing Required check.
+func promptValidator(p catalog.Prompt) (*fieldValidator, error) {
+	switch p.Type {
+	case "":
+		return nil, nil
+	case "regex":
+		return newRegexValidator(p.Validate)
+	case "enum":
+		return newEnumValidator(p.Choices), nil
+	case "range":
+		min, max, err := parseRangeSpec(p.Validate)
+		if err != nil {
+			return nil, err
+		}
+		return newRangeValidator(min, max), nil
+	case "path":
+		pathKind := p.Validate
+		if pathKind == "" {
+			pathKind = "file"
+		}
+		return newPathValidator(pathKind), nil
+	default:
+		return nil, fmt.Errorf("unknown prompt type %q", p.Type)
+	}
+}
+
+// toCatalogFile converts cats into catalog.File, the inverse of fromCatalog,
+// so --dump-config can print whatever command set is currently active
+// (built-in or loaded) in the same shape commands.yaml expects.
+func toCatalogFile(cats []category) *catalog.File {
+	f := &catalog.File{}
+	for _, cat := range cats {
+		if cat.name == recentCategoryName {
+			continue
+		}
+		cc := catalog.Category{Name: cat.name, Icon: cat.icon}
+		for _, c := range cat.commands {
+			cmd := catalog.Command{
+				Title:       c.title,
+				Description: c.description,
+				Script:      c.script,
+				Args:        c.args,
+				Destructive: c.destructive,
+				Env:         c.env,
+				Tags:        c.tags,
+				Mode:        c.execMode.catalogString(),
+			}
+			for _, p := range c.prompts {
+				prompt := catalog.Prompt{
+					Label:       p.label,
+					Placeholder: p.placeholder,
+					Required:    p.required,
+					Flag:        p.flag,
+					EmptyAdds:   p.emptyAdds,
+					Sensitive:   p.sensitive,
+				}
+				if v := p.validator; v != nil {
+					prompt.Type = v.kind
+					switch v.kind {
+					case "regex":
+						prompt.Validate = v.pattern.String()
+					case "enum":
+						prompt.Choices = v.choices
+					case "range":
+						prompt.Validate = fmt.Sprintf("%g:%g", v.min, v.max)
+					case "path":
+						prompt.Validate = v.pathKind
+					}
+				}
+				cmd.Prompts = append(cmd.Prompts, prompt)
+			}
+			cc.Commands = append(cc.Commands, cmd)
+		}
+		f.Categories = append(f.Categories, cc)
+	}
+	return f
+}
+
+// dumpConfig implements --dump-config: it prints the command set the console
+// would currently load (a hand-edited catalog if one exists, otherwise the
+// built-in defaults) as commands.yaml, giving operators a seed to edit.
+func dumpConfig() int {
+	root := os.Getenv("OPENEMR_EKS_PROJECT_ROOT")
+	if root == "" {
+		root = embeddedProjectRoot
+	}
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	cats := loadCategories(root, scriptsDir(root))
+	data, err := yaml.Marshal(toCatalogFile(cats))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode config: %s\n", err)
+		return 1
+	}
+	os.Stdout.Write(data)
+	return 0
+}
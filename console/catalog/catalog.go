@@ -0,0 +1,186 @@
+// Package catalog loads the console's menu of categories and commands from
+// an external commands.yaml file, so operators can add or tweak maintenance
+// scripts without recompiling the TUI. JSON is accepted too (YAML is a
+// superset of JSON), so --validate-catalog and friends work the same either
+// way.
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Prompt describes one value collected from the user before a command runs.
+// Type/Validate/Choices select an optional validator (see
+// promptValidator in the console package); EmptyAdds is a bare arg appended
+// instead of the flag/value pair when an optional field is left blank (e.g.
+// restore.sh's Snapshot prompt uses it to mean "use the latest snapshot").
+// Sensitive marks a field (e.g. a password or API key) whose submitted
+// value must never be written to the persisted history file.
+type Prompt struct {
+	Label       string   `yaml:"label"`
+	Placeholder string   `yaml:"placeholder"`
+	Required    bool     `yaml:"required"`
+	Flag        string   `yaml:"flag"`
+	Validate    string   `yaml:"validate"`
+	Default     string   `yaml:"default"`
+	Type        string   `yaml:"type"`
+	Choices     []string `yaml:"choices"`
+	EmptyAdds   string   `yaml:"empty_adds"`
+	Sensitive   bool     `yaml:"sensitive"`
+}
+
+// When gates a command's availability on the local environment.
+type When struct {
+	RequiresEnv  []string `yaml:"requires_env"`
+	RequiresFile string   `yaml:"requires_file"`
+}
+
+// Command is one menu entry as it appears in commands.yaml. Platforms
+// restricts which GOOS values the command is shown on (empty means all);
+// Env sets additional environment variables for the script's process; Tags
+// are free-form labels ops teams can use to group or filter commands in a
+// site-specific catalog. Mode is "inline" (the default, when empty) to
+// stream the script's output into the TUI, or "new-window" for scripts
+// that need a real interactive TTY (e.g. a sudo or MFA prompt) and so must
+// run in a spawned external terminal instead.
+type Command struct {
+	Title       string            `yaml:"title"`
+	Description string            `yaml:"description"`
+	Script      string            `yaml:"script"`
+	Args        []string          `yaml:"args"`
+	Destructive bool              `yaml:"destructive"`
+	Prompts     []Prompt          `yaml:"prompts"`
+	When        *When             `yaml:"when"`
+	Platforms   []string          `yaml:"platforms"`
+	Env         map[string]string `yaml:"env"`
+	Tags        []string          `yaml:"tags"`
+	Mode        string            `yaml:"mode"`
+}
+
+// Category groups related commands under a labeled menu section.
+type Category struct {
+	Name     string    `yaml:"name"`
+	Icon     string    `yaml:"icon"`
+	Commands []Command `yaml:"commands"`
+}
+
+// File is the top-level shape of commands.yaml.
+type File struct {
+	Categories []Category `yaml:"categories"`
+}
+
+// Load reads and validates the catalog file at path. Unknown fields are
+// rejected so a typo in a catalog (e.g. "destuctive") fails loudly at
+// startup instead of silently doing nothing.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading catalog %s: %w", path, err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parsing catalog %s: %w", path, err)
+	}
+
+	if err := Validate(&f); err != nil {
+		return nil, fmt.Errorf("invalid catalog %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Validate checks structural rules that yaml decoding alone can't enforce:
+// every command needs a title and script, titles must be unique within a
+// category (duplicate titles would make menu selection ambiguous), and each
+// prompt's type/validate/choices combination must actually be usable.
+func Validate(f *File) error {
+	for _, cat := range f.Categories {
+		seen := make(map[string]bool, len(cat.Commands))
+		for _, cmd := range cat.Commands {
+			if cmd.Title == "" {
+				return fmt.Errorf("category %q: command missing a title", cat.Name)
+			}
+			if cmd.Script == "" {
+				return fmt.Errorf("category %q: command %q missing a script path", cat.Name, cmd.Title)
+			}
+			if seen[cmd.Title] {
+				return fmt.Errorf("category %q: duplicate command title %q", cat.Name, cmd.Title)
+			}
+			seen[cmd.Title] = true
+			for _, plat := range cmd.Platforms {
+				if plat != "linux" && plat != "darwin" && plat != "windows" {
+					return fmt.Errorf("category %q: command %q: unknown platform %q (expected linux, darwin, or windows)", cat.Name, cmd.Title, plat)
+				}
+			}
+			if cmd.Mode != "" && cmd.Mode != "inline" && cmd.Mode != "new-window" {
+				return fmt.Errorf("category %q: command %q: unknown mode %q (expected inline or new-window)", cat.Name, cmd.Title, cmd.Mode)
+			}
+			for _, p := range cmd.Prompts {
+				if err := validatePromptType(p); err != nil {
+					return fmt.Errorf("category %q: command %q: prompt %q: %w", cat.Name, cmd.Title, p.Label, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validatePromptType checks that p.Type is one the console knows how to
+// build a validator for, and that p.Validate/p.Choices are well-formed for
+// that type, so a typo'd pattern or range fails at load time instead of
+// silently doing nothing the first time a user submits the form.
+func validatePromptType(p Prompt) error {
+	switch p.Type {
+	case "":
+		return nil
+	case "regex":
+		if _, err := regexp.Compile(p.Validate); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", p.Validate, err)
+		}
+	case "enum":
+		if len(p.Choices) == 0 {
+			return fmt.Errorf("type enum requires at least one choice")
+		}
+	case "range":
+		lo, hi, ok := strings.Cut(p.Validate, ":")
+		if !ok {
+			return fmt.Errorf("range spec %q must be \"min:max\"", p.Validate)
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(lo), 64); err != nil {
+			return fmt.Errorf("range spec %q: invalid min: %w", p.Validate, err)
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(hi), 64); err != nil {
+			return fmt.Errorf("range spec %q: invalid max: %w", p.Validate, err)
+		}
+	case "path":
+		if p.Validate != "" && p.Validate != "file" && p.Validate != "dir" && p.Validate != "writable" {
+			return fmt.Errorf("path validate must be \"file\", \"dir\", or \"writable\", got %q", p.Validate)
+		}
+	default:
+		return fmt.Errorf("unknown prompt type %q", p.Type)
+	}
+	return nil
+}
+
+// Discover looks for a commands.yaml (or commands.json) at projectRoot. It
+// returns "", nil when neither is present, signaling callers to fall back
+// to the built-in catalog.
+func Discover(projectRoot string) (string, error) {
+	for _, name := range []string{"commands.yaml", "commands.yml", "commands.json"} {
+		p := filepath.Join(projectRoot, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,202 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "commands.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	return path
+}
+
+func TestLoadValidCatalog(t *testing.T) {
+	path := writeFixture(t, `
+categories:
+  - name: Deployment
+    icon: "🚀"
+    commands:
+      - title: Quick Deploy
+        description: Deploy everything
+        script: scripts/quick-deploy.sh
+`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Categories) != 1 || len(f.Categories[0].Commands) != 1 {
+		t.Fatalf("unexpected catalog: %+v", f)
+	}
+	if f.Categories[0].Commands[0].Title != "Quick Deploy" {
+		t.Errorf("unexpected title: %q", f.Categories[0].Commands[0].Title)
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := writeFixture(t, `
+categories:
+  - name: Deployment
+    commands:
+      - title: Quick Deploy
+        script: scripts/quick-deploy.sh
+        destuctive: true
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for unknown field 'destuctive'")
+	}
+}
+
+func TestLoadRejectsMissingScript(t *testing.T) {
+	path := writeFixture(t, `
+categories:
+  - name: Deployment
+    commands:
+      - title: Quick Deploy
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for missing script")
+	}
+}
+
+func TestLoadRejectsDuplicateTitles(t *testing.T) {
+	path := writeFixture(t, `
+categories:
+  - name: Deployment
+    commands:
+      - title: Quick Deploy
+        script: scripts/a.sh
+      - title: Quick Deploy
+        script: scripts/b.sh
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for duplicate titles")
+	}
+}
+
+func TestValidateRejectsUnknownPlatform(t *testing.T) {
+	f := &File{Categories: []Category{{
+		Name: "X",
+		Commands: []Command{
+			{Title: "Cmd", Script: "s.sh", Platforms: []string{"solaris"}},
+		},
+	}}}
+	if err := Validate(f); err == nil {
+		t.Error("expected an error for an unrecognized platform")
+	}
+}
+
+func TestValidateRejectsUnknownMode(t *testing.T) {
+	f := &File{Categories: []Category{{
+		Name: "X",
+		Commands: []Command{
+			{Title: "Cmd", Script: "s.sh", Mode: "detached"},
+		},
+	}}}
+	if err := Validate(f); err == nil {
+		t.Error("expected an error for an unrecognized mode")
+	}
+}
+
+func TestValidateAcceptsKnownModes(t *testing.T) {
+	f := &File{Categories: []Category{{
+		Name: "X",
+		Commands: []Command{
+			{Title: "A", Script: "a.sh"},
+			{Title: "B", Script: "b.sh", Mode: "inline"},
+			{Title: "C", Script: "c.sh", Mode: "new-window"},
+		},
+	}}}
+	if err := Validate(f); err != nil {
+		t.Errorf("unexpected error for known modes: %v", err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestEnabledFiltersOnRequiresEnv(t *testing.T) {
+	f := &File{Categories: []Category{
+		{Name: "Cat", Commands: []Command{
+			{Title: "Gated", Script: "s.sh", When: &When{RequiresEnv: []string{"NOT_SET_XYZ"}}},
+			{Title: "Open", Script: "s.sh"},
+		}},
+	}}
+	enabled := f.Enabled(t.TempDir())
+	if len(enabled) != 1 || len(enabled[0].Commands) != 1 || enabled[0].Commands[0].Title != "Open" {
+		t.Errorf("expected only 'Open' to remain, got %+v", enabled)
+	}
+}
+
+func TestEnabledDropsEmptyCategories(t *testing.T) {
+	f := &File{Categories: []Category{
+		{Name: "AllGated", Commands: []Command{
+			{Title: "Gated", Script: "s.sh", When: &When{RequiresEnv: []string{"NOT_SET_XYZ"}}},
+		}},
+	}}
+	if enabled := f.Enabled(t.TempDir()); len(enabled) != 0 {
+		t.Errorf("expected category with no enabled commands to be dropped, got %+v", enabled)
+	}
+}
+
+func TestEnabledFiltersOnPlatform(t *testing.T) {
+	f := &File{Categories: []Category{
+		{Name: "Cat", Commands: []Command{
+			{Title: "WindowsOnly", Script: "s.sh", Platforms: []string{"windows"}},
+			{Title: "AnyPlatform", Script: "s.sh"},
+		}},
+	}}
+	enabled := f.Enabled(t.TempDir())
+	var titles []string
+	for _, c := range enabled[0].Commands {
+		titles = append(titles, c.Title)
+	}
+	if len(titles) != 1 || titles[0] != "AnyPlatform" {
+		t.Errorf("expected only commands matching the current platform, got %+v", titles)
+	}
+}
+
+func TestEnabledRequiresFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "present.txt"), []byte("x"), 0644)
+	f := &File{Categories: []Category{
+		{Name: "Cat", Commands: []Command{
+			{Title: "Present", Script: "s.sh", When: &When{RequiresFile: "present.txt"}},
+			{Title: "Missing", Script: "s.sh", When: &When{RequiresFile: "missing.txt"}},
+		}},
+	}}
+	enabled := f.Enabled(dir)
+	if len(enabled[0].Commands) != 1 || enabled[0].Commands[0].Title != "Present" {
+		t.Errorf("expected only 'Present' to remain, got %+v", enabled)
+	}
+}
+
+func TestDiscoverFindsCommandsYaml(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "commands.yaml"), []byte("categories: []"), 0644)
+	path, err := Discover(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path == "" {
+		t.Error("expected to discover commands.yaml")
+	}
+}
+
+func TestDiscoverReturnsEmptyWhenAbsent(t *testing.T) {
+	path, err := Discover(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("expected empty path, got %q", path)
+	}
+}
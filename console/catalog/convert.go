@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"os"
+	"runtime"
+)
+
+// Guard reports whether a command's "when" conditions are satisfied in the
+// current environment: every listed env var must be set, and, if given, the
+// required file must exist relative to projectRoot.
+func (w *When) Guard(projectRoot string) bool {
+	if w == nil {
+		return true
+	}
+	for _, name := range w.RequiresEnv {
+		if os.Getenv(name) == "" {
+			return false
+		}
+	}
+	if w.RequiresFile != "" {
+		if _, err := os.Stat(projectRoot + string(os.PathSeparator) + w.RequiresFile); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// platformMatches reports whether cmd should be shown on the current GOOS: an
+// empty Platforms list means every platform.
+func platformMatches(cmd Command) bool {
+	if len(cmd.Platforms) == 0 {
+		return true
+	}
+	for _, p := range cmd.Platforms {
+		if p == runtime.GOOS {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled filters f's categories down to commands whose "when" guard
+// currently passes and whose Platforms (if any) includes the current GOOS,
+// dropping categories that end up with no commands left. It does not mutate
+// f.
+func (f *File) Enabled(projectRoot string) []Category {
+	var out []Category
+	for _, cat := range f.Categories {
+		var kept []Command
+		for _, cmd := range cat.Commands {
+			if cmd.When.Guard(projectRoot) && platformMatches(cmd) {
+				kept = append(kept, cmd)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		out = append(out, Category{Name: cat.Name, Icon: cat.Icon, Commands: kept})
+	}
+	return out
+}
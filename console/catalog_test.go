@@ -0,0 +1,306 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	cat "github.com/openemr/openemr-on-eks/console/catalog"
+)
+
+func TestFromCatalogMapsFields(t *testing.T) {
+	cats := []cat.Category{
+		{
+			Name: "Deployment",
+			Icon: "🚀",
+			Commands: []cat.Command{
+				{
+					Title:       "Quick Deploy",
+					Description: "Deploy everything",
+					Script:      "scripts/quick-deploy.sh",
+					Destructive: true,
+					Prompts: []cat.Prompt{
+						{Label: "Bucket", Required: true, Flag: "bucket"},
+					},
+				},
+			},
+		},
+	}
+
+	out := fromCatalog(cats)
+	if len(out) != 1 || len(out[0].commands) != 1 {
+		t.Fatalf("unexpected conversion: %+v", out)
+	}
+	cmd := out[0].commands[0]
+	if cmd.title != "Quick Deploy" || !cmd.destructive {
+		t.Errorf("unexpected command: %+v", cmd)
+	}
+	if len(cmd.prompts) != 1 || cmd.prompts[0].flag != "bucket" {
+		t.Errorf("unexpected prompts: %+v", cmd.prompts)
+	}
+}
+
+func TestFromCatalogBuildsPromptValidators(t *testing.T) {
+	cats := []cat.Category{
+		{
+			Name: "Deployment",
+			Commands: []cat.Command{
+				{
+					Title:  "Pick Region",
+					Script: "s.sh",
+					Prompts: []cat.Prompt{
+						{Label: "Region", Type: "regex", Validate: `^us-[a-z]+-\d$`},
+						{Label: "Version", Type: "enum", Choices: []string{"7.0", "7.0.1"}},
+					},
+				},
+			},
+		},
+	}
+
+	out := fromCatalog(cats)
+	prompts := out[0].commands[0].prompts
+	if prompts[0].validator == nil || prompts[0].validator.kind != "regex" {
+		t.Errorf("expected a regex validator, got %+v", prompts[0].validator)
+	}
+	if prompts[1].validator == nil || prompts[1].validator.kind != "enum" {
+		t.Errorf("expected an enum validator, got %+v", prompts[1].validator)
+	}
+}
+
+func TestCatalogValidateRejectsBadPromptType(t *testing.T) {
+	f := &cat.File{Categories: []cat.Category{{
+		Name: "X",
+		Commands: []cat.Command{{
+			Title:  "Cmd",
+			Script: "s.sh",
+			Prompts: []cat.Prompt{
+				{Label: "Region", Type: "regex", Validate: "["},
+			},
+		}},
+	}}}
+	if err := cat.Validate(f); err == nil {
+		t.Error("expected an error for an invalid regex prompt")
+	}
+}
+
+func TestCatalogValidateRejectsBadRangeSpec(t *testing.T) {
+	f := &cat.File{Categories: []cat.Category{{
+		Name: "X",
+		Commands: []cat.Command{{
+			Title:  "Cmd",
+			Script: "s.sh",
+			Prompts: []cat.Prompt{
+				{Label: "Count", Type: "range", Validate: "not-a-range"},
+			},
+		}},
+	}}}
+	if err := cat.Validate(f); err == nil {
+		t.Error("expected an error for a malformed range spec")
+	}
+}
+
+func TestCatalogValidateRejectsEnumWithoutChoices(t *testing.T) {
+	f := &cat.File{Categories: []cat.Category{{
+		Name: "X",
+		Commands: []cat.Command{{
+			Title:  "Cmd",
+			Script: "s.sh",
+			Prompts: []cat.Prompt{
+				{Label: "Version", Type: "enum"},
+			},
+		}},
+	}}}
+	if err := cat.Validate(f); err == nil {
+		t.Error("expected an error for an enum prompt with no choices")
+	}
+}
+
+func TestLoadCategoriesFallsBackWithoutCatalog(t *testing.T) {
+	dir := t.TempDir()
+	cats := loadCategories(dir, filepath.Join(dir, "scripts"))
+	if len(cats) == 0 {
+		t.Error("expected built-in catalog when no commands.yaml is present")
+	}
+}
+
+func TestLoadCategoriesFallsBackOnInvalidCatalog(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "commands.yaml"), []byte("categories:\n  - name: X\n    commands:\n      - title: NoScript\n"), 0644)
+	cats := loadCategories(dir, filepath.Join(dir, "scripts"))
+	if len(cats) == 0 {
+		t.Error("expected fallback to built-in catalog on invalid commands.yaml")
+	}
+}
+
+func TestLoadCategoriesUsesValidCatalog(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "commands.yaml"), []byte("categories:\n  - name: X\n    commands:\n      - title: Only\n        script: s.sh\n"), 0644)
+	cats := loadCategories(dir, filepath.Join(dir, "scripts"))
+	if len(cats) != 1 || cats[0].name != "X" {
+		t.Errorf("expected loaded catalog to be used, got %+v", cats)
+	}
+}
+
+func TestLoadCategoriesHonorsConfigOverride(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "elsewhere.yaml")
+	os.WriteFile(other, []byte("categories:\n  - name: Y\n    commands:\n      - title: Only\n        script: s.sh\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "commands.yaml"), []byte("categories:\n  - name: X\n    commands:\n      - title: Only\n        script: s.sh\n"), 0644)
+
+	catalogPathOverride = other
+	defer func() { catalogPathOverride = "" }()
+
+	cats := loadCategories(dir, filepath.Join(dir, "scripts"))
+	if len(cats) != 1 || cats[0].name != "Y" {
+		t.Errorf("expected --config override to take precedence over discovery, got %+v", cats)
+	}
+}
+
+func TestToCatalogFileRoundTripsThroughFromCatalog(t *testing.T) {
+	original := []cat.Category{
+		{
+			Name: "Deployment",
+			Icon: "🚀",
+			Commands: []cat.Command{
+				{
+					Title:       "Quick Deploy",
+					Description: "Deploy everything",
+					Script:      "scripts/quick-deploy.sh",
+					Destructive: true,
+					Prompts:     []cat.Prompt{{Label: "Bucket", Required: true, Flag: "bucket"}},
+				},
+			},
+		},
+	}
+
+	dumped := toCatalogFile(fromCatalog(original))
+	if len(dumped.Categories) != 1 || len(dumped.Categories[0].Commands) != 1 {
+		t.Fatalf("unexpected round trip: %+v", dumped)
+	}
+	cmd := dumped.Categories[0].Commands[0]
+	if cmd.Title != "Quick Deploy" || !cmd.Destructive || cmd.Prompts[0].Flag != "bucket" {
+		t.Errorf("unexpected round-tripped command: %+v", cmd)
+	}
+}
+
+func TestFromCatalogAndToCatalogFileRoundTripSensitive(t *testing.T) {
+	original := []cat.Category{
+		{
+			Name: "Deployment",
+			Commands: []cat.Command{
+				{
+					Title:   "Rotate Secret",
+					Script:  "scripts/rotate-secret.sh",
+					Prompts: []cat.Prompt{{Label: "APIKey", Required: true, Sensitive: true}},
+				},
+			},
+		},
+	}
+
+	cats := fromCatalog(original)
+	if !cats[0].commands[0].prompts[0].sensitive {
+		t.Fatal("expected fromCatalog to carry Sensitive through to inputField")
+	}
+
+	dumped := toCatalogFile(cats)
+	if !dumped.Categories[0].Commands[0].Prompts[0].Sensitive {
+		t.Error("expected toCatalogFile to carry sensitive back to catalog.Prompt")
+	}
+}
+
+func TestFromCatalogAndToCatalogFileRoundTripEnvAndTags(t *testing.T) {
+	original := []cat.Category{
+		{
+			Name: "Deployment",
+			Commands: []cat.Command{
+				{
+					Title:  "Quick Deploy",
+					Script: "scripts/quick-deploy.sh",
+					Env:    map[string]string{"AWS_PROFILE": "training"},
+					Tags:   []string{"deploy", "training"},
+				},
+			},
+		},
+	}
+
+	cats := fromCatalog(original)
+	cmd := cats[0].commands[0]
+	if cmd.env["AWS_PROFILE"] != "training" {
+		t.Errorf("expected fromCatalog to carry Env through, got %+v", cmd.env)
+	}
+	if len(cmd.tags) != 2 || cmd.tags[0] != "deploy" {
+		t.Errorf("expected fromCatalog to carry Tags through, got %+v", cmd.tags)
+	}
+
+	dumped := toCatalogFile(cats)
+	dc := dumped.Categories[0].Commands[0]
+	if dc.Env["AWS_PROFILE"] != "training" || len(dc.Tags) != 2 {
+		t.Errorf("expected toCatalogFile to carry env/tags back, got %+v", dc)
+	}
+}
+
+func TestFromCatalogAndToCatalogFileRoundTripMode(t *testing.T) {
+	original := []cat.Category{
+		{
+			Name: "Deployment",
+			Commands: []cat.Command{
+				{Title: "Quick Deploy", Script: "scripts/quick-deploy.sh"},
+				{Title: "Open AWS Console", Script: "scripts/open-console.sh", Mode: "new-window"},
+			},
+		},
+	}
+
+	cats := fromCatalog(original)
+	if cats[0].commands[0].execMode != modeInline {
+		t.Errorf("expected an empty Mode to convert to modeInline, got %v", cats[0].commands[0].execMode)
+	}
+	if cats[0].commands[1].execMode != modeNewWindow {
+		t.Errorf("expected Mode %q to convert to modeNewWindow, got %v", "new-window", cats[0].commands[1].execMode)
+	}
+
+	dumped := toCatalogFile(cats)
+	if dumped.Categories[0].Commands[0].Mode != "" {
+		t.Errorf("expected modeInline to dump as an empty Mode, got %q", dumped.Categories[0].Commands[0].Mode)
+	}
+	if dumped.Categories[0].Commands[1].Mode != "new-window" {
+		t.Errorf("expected modeNewWindow to dump as %q, got %q", "new-window", dumped.Categories[0].Commands[1].Mode)
+	}
+}
+
+func TestToCatalogFileDropsRecentCategory(t *testing.T) {
+	cats := []category{{name: recentCategoryName, commands: []command{{title: "Cmd1", script: "s.sh"}}}}
+	dumped := toCatalogFile(cats)
+	if len(dumped.Categories) != 0 {
+		t.Errorf("expected the synthetic Recent category to be excluded, got %+v", dumped.Categories)
+	}
+}
+
+func TestParseConfigFlagExtractsPathAndStripsArgs(t *testing.T) {
+	defer func() { catalogPathOverride = "" }()
+
+	remaining := parseConfigFlag([]string{"--config", "/tmp/commands.yaml", "list", "--json"})
+	if catalogPathOverride != "/tmp/commands.yaml" {
+		t.Errorf("catalogPathOverride = %q, want /tmp/commands.yaml", catalogPathOverride)
+	}
+	want := []string{"list", "--json"}
+	if len(remaining) != len(want) {
+		t.Fatalf("remaining = %v, want %v", remaining, want)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("remaining[%d] = %q, want %q", i, remaining[i], want[i])
+		}
+	}
+}
+
+func TestParseConfigFlagNoOpWithoutConfig(t *testing.T) {
+	catalogPathOverride = ""
+	args := []string{"list", "--json"}
+	remaining := parseConfigFlag(args)
+	if catalogPathOverride != "" {
+		t.Errorf("expected no override, got %q", catalogPathOverride)
+	}
+	if len(remaining) != 2 || remaining[0] != "list" {
+		t.Errorf("expected args unchanged, got %v", remaining)
+	}
+}
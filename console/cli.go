@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// runCLI dispatches the console's headless subcommands — run, list, describe,
+// validate, completion, and install-wrappers — so the same binary can be
+// driven from CI, cron, or shell scripts instead of the interactive Bubble
+// Tea UI. It returns the process exit code.
+func runCLI(args []string) int {
+	switch args[0] {
+	case "run":
+		return cliRun(args[1:])
+	case "list":
+		return cliList(args[1:])
+	case "describe":
+		return cliDescribe(args[1:])
+	case "validate":
+		return runValidateCatalog()
+	case "completion":
+		return cliCompletion(args[1:])
+	case "install-wrappers":
+		return cliInstallWrappers(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q (expected run, list, describe, validate, completion, or install-wrappers)\n", args[0])
+		return 1
+	}
+}
+
+// slugRe matches runs of characters other than lowercase ASCII letters and
+// digits (hyphens included), used to collapse them to a single hyphen when
+// slugifying.
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify kebab-cases s: "Quick Deploy" -> "quick-deploy".
+func slugify(s string) string {
+	s = slugRe.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(s, "-")
+}
+
+// findBySlug looks up a command by "<category-slug>/<title-slug>", the form
+// accepted by `run`, skipping the synthetic Recent category since it only
+// ever duplicates commands that live elsewhere.
+func findBySlug(cats []category, slug string) (cmd command, catName string, ok bool) {
+	catSlug, titleSlug, found := strings.Cut(slug, "/")
+	if !found {
+		return command{}, "", false
+	}
+	for _, cat := range cats {
+		if cat.name == recentCategoryName || slugify(cat.name) != catSlug {
+			continue
+		}
+		for _, c := range cat.commands {
+			if slugify(c.title) == titleSlug {
+				return c, cat.name, true
+			}
+		}
+	}
+	return command{}, "", false
+}
+
+// cliRun implements `openemr-eks run <category>/<title> [--flag value | --Label=value ...] [--yes] [--external-terminal]`:
+// it resolves the command by slug, maps flags onto its prompts, enforces
+// required fields, and runs the script synchronously with its output
+// connected directly to the CLI's own stdout/stderr. Destructive commands
+// require --yes, since there's no TUI to run the typing challenge.
+// --external-terminal launches the script in a detected terminal emulator
+// window instead, for scripts that need a real interactive TTY (see
+// runInExternalTerminal); it requires a graphical session and falls back to
+// an error rather than silently running inline, since that's exactly the
+// behavior the flag was there to avoid.
+func cliRun(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: openemr-eks run <category>/<title> [--flag value | --Label=value ...] [--yes] [--external-terminal]")
+		return 1
+	}
+
+	root := resolveProjectRoot()
+	cats := loadCategories(root, scriptsDir(root))
+
+	cmd, _, ok := findBySlug(cats, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "no command matches %q (see `openemr-eks list`)\n", args[0])
+		return 1
+	}
+
+	flagArgs, confirmed := stripYesFlag(args[1:])
+	if cmd.destructive && !confirmed {
+		fmt.Fprintf(os.Stderr, "%q is destructive; pass --yes to confirm running it non-interactively\n", args[0])
+		return 1
+	}
+
+	flagArgs, external := stripExternalTerminalFlag(flagArgs)
+
+	cmdArgs, err := resolveHeadlessArgs(cmd, flagArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if external {
+		if err := runInExternalTerminal(cmd, cmdArgs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	return runHeadless(cmd, cmdArgs)
+}
+
+// stripExternalTerminalFlag removes a bare "--external-terminal" from
+// flagArgs, reporting whether it was present, the same pattern stripYesFlag
+// uses for "--yes".
+func stripExternalTerminalFlag(flagArgs []string) (remaining []string, external bool) {
+	for _, a := range flagArgs {
+		if a == "--external-terminal" {
+			external = true
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	return remaining, external
+}
+
+// stripYesFlag removes a bare "--yes" from flagArgs, reporting whether it was
+// present. It's the non-interactive stand-in for the TUI's typing challenge:
+// destructive commands refuse to run headlessly without it.
+func stripYesFlag(flagArgs []string) (remaining []string, yes bool) {
+	for _, a := range flagArgs {
+		if a == "--yes" {
+			yes = true
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	return remaining, yes
+}
+
+// resolveHeadlessArgs maps flags from flagArgs onto cmd's prompts and
+// appends them to the command's base args in prompt order, exactly as
+// buildArgsFromInput does for the interactive form. Two flag syntaxes are
+// accepted, matched in the order that disambiguates them:
+//
+//   - "--flag value", matched against each field's flag name (the form a
+//     field's own --flag documents, e.g. --bucket my-bucket)
+//   - "--Label=value", matched against each field's display label (handy
+//     for fields with no flag name, or when scripting against the same
+//     labels the TUI shows, e.g. --Bucket=my-bucket)
+//
+// It returns an error if a required field has no matching value.
+func resolveHeadlessArgs(cmd command, flagArgs []string) ([]string, error) {
+	byFlag := make(map[string]string, len(flagArgs))
+	byLabel := make(map[string]string, len(flagArgs))
+	for i := 0; i < len(flagArgs); i++ {
+		name, ok := strings.CutPrefix(flagArgs[i], "--")
+		if !ok {
+			return nil, fmt.Errorf("unexpected argument %q (expected --flag value or --Label=value)", flagArgs[i])
+		}
+		if label, value, found := strings.Cut(name, "="); found {
+			byLabel[label] = value
+			continue
+		}
+		if i+1 >= len(flagArgs) {
+			return nil, fmt.Errorf("flag --%s is missing a value", name)
+		}
+		i++
+		byFlag[name] = flagArgs[i]
+	}
+
+	inp := newInputState(cmd.prompts)
+	for i, f := range cmd.prompts {
+		if v, ok := byLabel[f.label]; ok {
+			inp.values[i] = v
+		} else if f.flag != "" {
+			if v, ok := byFlag[f.flag]; ok {
+				inp.values[i] = v
+			}
+		}
+	}
+	for i, f := range cmd.prompts {
+		if msg := inp.fieldError(i); msg != "" {
+			return nil, fmt.Errorf("%s: %s", f.label, msg)
+		}
+	}
+
+	return buildArgsFromInput(cmd, inp), nil
+}
+
+// cliDescribe implements `openemr-eks describe <category>/<title>`, printing
+// the command's metadata and prompt fields so a script author can see what
+// --flag/--Label=value arguments `run` expects without opening the catalog.
+func cliDescribe(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: openemr-eks describe <category>/<title>")
+		return 1
+	}
+
+	root := resolveProjectRoot()
+	cats := loadCategories(root, scriptsDir(root))
+
+	cmd, catName, ok := findBySlug(cats, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "no command matches %q (see `openemr-eks list`)\n", args[0])
+		return 1
+	}
+
+	fmt.Println(describeCommand(cmd, catName))
+	return 0
+}
+
+// describeCommand renders cmd's metadata and prompt fields as the text
+// printed by `describe`, kept separate from cliDescribe so it can be tested
+// without touching the filesystem (mirroring how buildCLICatalog is the
+// testable core of `list`).
+func describeCommand(cmd command, catName string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n", cmd.title, catName)
+	fmt.Fprintln(&b, cmd.description)
+	if cmd.destructive {
+		fmt.Fprintln(&b, "destructive: requires --yes to run non-interactively")
+	}
+	if len(cmd.prompts) == 0 {
+		fmt.Fprint(&b, "no prompt fields")
+		return b.String()
+	}
+
+	fmt.Fprint(&b, "fields:")
+	for _, f := range cmd.prompts {
+		requirement := "optional"
+		if f.required {
+			requirement = "required"
+		}
+		flag := "(positional, no flag)"
+		if f.flag != "" {
+			flag = "--" + f.flag
+		}
+		fmt.Fprintf(&b, "\n  %s\t%s\t%s", f.label, requirement, flag)
+		if f.placeholder != "" {
+			fmt.Fprintf(&b, "\tplaceholder=%s", f.placeholder)
+		}
+		if f.emptyAdds != "" {
+			fmt.Fprintf(&b, "\tempty-adds=%s", f.emptyAdds)
+		}
+	}
+	return b.String()
+}
+
+// runHeadless runs cmd's script synchronously with stdout/stderr connected
+// directly to the CLI's own, returning the script's exit code (or 1 if it
+// couldn't be started at all).
+func runHeadless(cmd command, args []string) int {
+	if _, err := os.Stat(cmd.script); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "script not found: %s\n", cmd.script)
+		return 1
+	}
+	os.Chmod(cmd.script, 0755)
+
+	execCmd := exec.Command("bash", append([]string{cmd.script}, args...)...)
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	execCmd.Stdin = os.Stdin
+
+	if err := execCmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "failed to run script: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// cliCommand and cliCategory are the stable JSON shape emitted by
+// `openemr-eks list --json`, independent of the internal category/command
+// struct layout so that shape can evolve without breaking scripts parsing it.
+type cliCommand struct {
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Destructive bool     `json:"destructive"`
+	Flags       []string `json:"flags"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type cliCategory struct {
+	Name     string       `json:"name"`
+	Commands []cliCommand `json:"commands"`
+}
+
+// buildCLICatalog converts cats into the stable JSON/text shape used by
+// `list`, dropping the synthetic Recent category since it only ever
+// duplicates commands that live elsewhere.
+func buildCLICatalog(cats []category) []cliCategory {
+	var out []cliCategory
+	for _, cat := range cats {
+		if cat.name == recentCategoryName {
+			continue
+		}
+		cc := cliCategory{Name: cat.name}
+		for _, c := range cat.commands {
+			var flags []string
+			for _, f := range c.prompts {
+				if f.flag != "" {
+					flags = append(flags, f.flag)
+				}
+			}
+			cc.Commands = append(cc.Commands, cliCommand{
+				Slug:        slugify(cat.name) + "/" + slugify(c.title),
+				Title:       c.title,
+				Description: c.description,
+				Destructive: c.destructive,
+				Flags:       flags,
+				Tags:        c.tags,
+			})
+		}
+		out = append(out, cc)
+	}
+	return out
+}
+
+// filterCLICatalogByTag narrows cats down to commands carrying tag, dropping
+// categories that end up with no commands left.
+func filterCLICatalogByTag(cats []cliCategory, tag string) []cliCategory {
+	var out []cliCategory
+	for _, cat := range cats {
+		var kept []cliCommand
+		for _, c := range cat.Commands {
+			for _, t := range c.Tags {
+				if t == tag {
+					kept = append(kept, c)
+					break
+				}
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		out = append(out, cliCategory{Name: cat.Name, Commands: kept})
+	}
+	return out
+}
+
+// cliList implements `openemr-eks list [--json] [--tag <tag>]`.
+func cliList(args []string) int {
+	jsonOut := false
+	tag := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--json":
+			jsonOut = true
+		case "--tag":
+			if i+1 < len(args) {
+				tag = args[i+1]
+				i++
+			}
+		}
+	}
+
+	root := resolveProjectRoot()
+	cats := loadCategories(root, scriptsDir(root))
+	out := buildCLICatalog(cats)
+	if tag != "" {
+		out = filterCLICatalogByTag(out, tag)
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode catalog: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, cat := range out {
+		fmt.Println(cat.Name)
+		for _, c := range cat.Commands {
+			fmt.Printf("  %s\t%s\n", c.Slug, c.Description)
+		}
+	}
+	return 0
+}
+
+// cliCompletion implements `openemr-eks completion {bash|zsh|fish}`, printing
+// a shell completion script for the run/list/describe/validate/completion/
+// install-wrappers subcommands to stdout.
+func cliCompletion(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: openemr-eks completion {bash|zsh|fish}")
+		return 1
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Println(`_openemr_eks_completions() {
+  COMPREPLY=($(compgen -W "run list describe validate completion install-wrappers" -- "${COMP_WORDS[1]}"))
+}
+complete -F _openemr_eks_completions openemr-eks`)
+	case "zsh":
+		fmt.Println(`#compdef openemr-eks
+_arguments '1: :(run list describe validate completion install-wrappers)'`)
+	case "fish":
+		fmt.Println(`complete -c openemr-eks -n "__fish_use_subcommand" -a "run list describe validate completion install-wrappers"`)
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported shell %q (expected bash, zsh, or fish)\n", args[0])
+		return 1
+	}
+	return 0
+}
+
+// scriptsDir returns the scripts/ directory for a resolved project root.
+func scriptsDir(projectRoot string) string {
+	return filepath.Join(projectRoot, "scripts")
+}
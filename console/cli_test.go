@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Quick Deploy":      "quick-deploy",
+		"Backup & Recovery": "backup-recovery",
+		"  Spaced  ":        "spaced",
+	}
+	for in, want := range cases {
+		if got := slugify(in); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindBySlugMatchesCategoryAndTitle(t *testing.T) {
+	cmd, catName, ok := findBySlug(testCategories(), "alpha/cmd1")
+	if !ok {
+		t.Fatal("expected to find alpha/cmd1")
+	}
+	if cmd.title != "Cmd1" || catName != "Alpha" {
+		t.Errorf("unexpected match: %+v in %q", cmd, catName)
+	}
+}
+
+func TestFindBySlugSkipsRecentCategory(t *testing.T) {
+	cats := append([]category{{name: recentCategoryName, commands: []command{{title: "Cmd1"}}}}, testCategories()...)
+	_, catName, ok := findBySlug(cats, "recent/cmd1")
+	if ok {
+		t.Errorf("expected the synthetic Recent category not to be addressable by slug, got match in %q", catName)
+	}
+}
+
+func TestFindBySlugUnknown(t *testing.T) {
+	if _, _, ok := findBySlug(testCategories(), "alpha/does-not-exist"); ok {
+		t.Error("expected no match for an unknown slug")
+	}
+}
+
+func TestResolveHeadlessArgsMapsFlagsByName(t *testing.T) {
+	cmd := command{
+		script: "/tmp/restore.sh",
+		prompts: []inputField{
+			{label: "Bucket", flag: "bucket", required: true},
+			{label: "Snapshot", flag: "snapshot"},
+		},
+	}
+	args, err := resolveHeadlessArgs(cmd, []string{"--bucket", "my-bucket", "--snapshot", "snap-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--bucket", "my-bucket", "--snapshot", "snap-1"}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("args[%d] = %q, want %q", i, args[i], want[i])
+		}
+	}
+}
+
+func TestResolveHeadlessArgsMissingRequiredField(t *testing.T) {
+	cmd := command{
+		script:  "/tmp/restore.sh",
+		prompts: []inputField{{label: "Bucket", flag: "bucket", required: true}},
+	}
+	if _, err := resolveHeadlessArgs(cmd, nil); err == nil {
+		t.Error("expected an error for a missing required flag")
+	}
+}
+
+func TestResolveHeadlessArgsRejectsMalformedFlag(t *testing.T) {
+	cmd := command{script: "/tmp/a.sh"}
+	if _, err := resolveHeadlessArgs(cmd, []string{"bucket"}); err == nil {
+		t.Error("expected an error for an argument not starting with --")
+	}
+}
+
+func TestBuildCLICatalogSchemaIsStable(t *testing.T) {
+	cats := testCategories()
+	out := buildCLICatalog(cats)
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(decoded))
+	}
+	firstCmd := decoded[0]["commands"].([]interface{})[0].(map[string]interface{})
+	for _, field := range []string{"slug", "title", "description", "destructive", "flags"} {
+		if _, ok := firstCmd[field]; !ok {
+			t.Errorf("expected JSON field %q in command output, got %v", field, firstCmd)
+		}
+	}
+	if firstCmd["slug"] != "alpha/cmd1" {
+		t.Errorf("slug = %v, want %q", firstCmd["slug"], "alpha/cmd1")
+	}
+}
+
+func TestStripYesFlag(t *testing.T) {
+	remaining, yes := stripYesFlag([]string{"--bucket", "my-bucket", "--yes"})
+	if !yes {
+		t.Error("expected --yes to be detected")
+	}
+	want := []string{"--bucket", "my-bucket"}
+	if len(remaining) != len(want) {
+		t.Fatalf("remaining = %v, want %v", remaining, want)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("remaining[%d] = %q, want %q", i, remaining[i], want[i])
+		}
+	}
+}
+
+func TestStripYesFlagAbsent(t *testing.T) {
+	remaining, yes := stripYesFlag([]string{"--bucket", "my-bucket"})
+	if yes {
+		t.Error("expected --yes to be absent")
+	}
+	if len(remaining) != 2 {
+		t.Errorf("remaining = %v, want unchanged args", remaining)
+	}
+}
+
+func TestResolveHeadlessArgsAcceptsLabelEqualsSyntax(t *testing.T) {
+	cmd := command{
+		script: "/tmp/restore.sh",
+		prompts: []inputField{
+			{label: "Bucket", flag: "bucket", required: true},
+			{label: "Snapshot", flag: "snapshot"},
+		},
+	}
+	args, err := resolveHeadlessArgs(cmd, []string{"--Bucket=my-bucket", "--Snapshot=snap-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"--bucket", "my-bucket", "--snapshot", "snap-1"}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("args[%d] = %q, want %q", i, args[i], want[i])
+		}
+	}
+}
+
+func TestResolveHeadlessArgsLabelEqualsWorksWithoutFlagName(t *testing.T) {
+	cmd := command{
+		script:  "/tmp/a.sh",
+		prompts: []inputField{{label: "Region", required: true}},
+	}
+	args, err := resolveHeadlessArgs(cmd, []string{"--Region=us-east-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"us-east-1"}
+	if len(args) != 1 || args[0] != want[0] {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+// TestArgParsingSymmetryWithTUIPath asserts that the CLI's resolveHeadlessArgs
+// and the TUI's buildArgsFromInput path produce identical args
+// for the same submitted values, including the restore.sh "leave blank for
+// latest snapshot" emptyAdds behavior, so both surfaces stay in lockstep.
+func TestArgParsingSymmetryWithTUIPath(t *testing.T) {
+	cmd := command{
+		script: "/tmp/scripts/restore.sh",
+		prompts: []inputField{
+			{label: "Bucket", flag: "bucket", required: true},
+			{label: "Snapshot", flag: "snapshot", emptyAdds: "--latest-snapshot"},
+		},
+	}
+
+	cliArgs, err := resolveHeadlessArgs(cmd, []string{"--bucket", "my-bucket"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tuiInput := newInputState(cmd.prompts)
+	tuiInput.values[0] = "my-bucket"
+	tuiArgs := buildArgsFromInput(cmd, tuiInput)
+
+	if len(cliArgs) != len(tuiArgs) {
+		t.Fatalf("cliArgs = %v, tuiArgs = %v: lengths differ", cliArgs, tuiArgs)
+	}
+	for i := range tuiArgs {
+		if cliArgs[i] != tuiArgs[i] {
+			t.Errorf("cliArgs[%d] = %q, tuiArgs[%d] = %q: want symmetry", i, cliArgs[i], i, tuiArgs[i])
+		}
+	}
+}
+
+func TestDescribeCommandListsFieldsWithRequirementAndFlag(t *testing.T) {
+	cmd := command{
+		title:       "Restore Deployment",
+		description: "Restore OpenEMR from a snapshot",
+		destructive: true,
+		prompts: []inputField{
+			{label: "Bucket", flag: "bucket", required: true, placeholder: "my-bucket"},
+			{label: "Snapshot", flag: "snapshot", emptyAdds: "--latest-snapshot"},
+		},
+	}
+	out := describeCommand(cmd, "Backup & Recovery")
+
+	for _, want := range []string{
+		"Restore Deployment (Backup & Recovery)",
+		"requires --yes",
+		"Bucket\trequired\t--bucket\tplaceholder=my-bucket",
+		"Snapshot\toptional\t--snapshot\tempty-adds=--latest-snapshot",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("describeCommand output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestDescribeCommandNoPromptFields(t *testing.T) {
+	cmd := command{title: "List Backups", description: "Lists available backups"}
+	out := describeCommand(cmd, "Backup & Recovery")
+	if !strings.Contains(out, "no prompt fields") {
+		t.Errorf("expected 'no prompt fields' for a command with none, got:\n%s", out)
+	}
+}
+
+func TestFilterCLICatalogByTagKeepsOnlyMatches(t *testing.T) {
+	cats := []cliCategory{{
+		Name: "Deployment",
+		Commands: []cliCommand{
+			{Slug: "deployment/quick-deploy", Tags: []string{"deploy", "training"}},
+			{Slug: "deployment/validate", Tags: []string{"validate"}},
+		},
+	}}
+	out := filterCLICatalogByTag(cats, "training")
+	if len(out) != 1 || len(out[0].Commands) != 1 || out[0].Commands[0].Slug != "deployment/quick-deploy" {
+		t.Errorf("expected only the tagged command to remain, got %+v", out)
+	}
+}
+
+func TestFilterCLICatalogByTagDropsEmptyCategories(t *testing.T) {
+	cats := []cliCategory{{
+		Name:     "Deployment",
+		Commands: []cliCommand{{Slug: "deployment/validate", Tags: []string{"validate"}}},
+	}}
+	if out := filterCLICatalogByTag(cats, "training"); len(out) != 0 {
+		t.Errorf("expected a category with no matching commands to be dropped, got %+v", out)
+	}
+}
+
+func TestBuildCLICatalogDropsRecentCategory(t *testing.T) {
+	cats := append([]category{{name: recentCategoryName, commands: []command{{title: "Cmd1"}}}}, testCategories()...)
+	out := buildCLICatalog(cats)
+	for _, c := range out {
+		if c.Name == recentCategoryName {
+			t.Error("expected the synthetic Recent category to be excluded from the CLI catalog")
+		}
+	}
+}
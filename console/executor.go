@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// maxLogLines caps the in-memory ring buffer of streamed output lines kept
+// for the executing view. Older lines are dropped once a run exceeds this,
+// since the terminal can only show a small window of it anyway and an
+// unbounded buffer would let a noisy script grow the TUI's memory without
+// limit.
+const maxLogLines = 2000
+
+// gracePeriod is how long a cancelled script is given to exit after SIGINT
+// before it is forcibly killed.
+const gracePeriod = 5 * time.Second
+
+// logLine is one line of output captured from a running script, tagged with
+// which stream it came from and when it arrived.
+type logLine struct {
+	stream string // "stdout" or "stderr"
+	text   string
+	ts     time.Time
+}
+
+// logLineMsg delivers a single captured output line to Update.
+type logLineMsg logLine
+
+// finishMsg closes out a streamed run: the process has exited (or was
+// cancelled), with its exit code and total duration.
+type finishMsg struct {
+	exitCode  int
+	duration  time.Duration
+	cancelled bool
+}
+
+// waitForLog blocks for the next message on ch and returns it. Update re-arms
+// this after every logLineMsg so the Tea runtime keeps draining the channel
+// one message at a time; back-pressure on ch just makes the producing
+// goroutine block briefly, it never blocks Update itself.
+func waitForLog(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// runScript starts cmd (with overrideArgs substituted for its static args, if
+// non-nil) running in the background, streaming its stdout/stderr into ch as
+// logLineMsg values and finishing with a single finishMsg. The returned
+// tea.Cmd only launches the goroutine and returns immediately (nil), so the
+// Tea event loop is never blocked on a running script; ch must already be
+// registered with a waitForLog(ch) listener by the caller.
+func runScript(ctx context.Context, cmd command, overrideArgs []string, ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		go streamScript(ctx, cmd, overrideArgs, ch)
+		return nil
+	}
+}
+
+// streamScript does the actual work of runScript's goroutine: validating the
+// script, running it under exec.CommandContext with output piped line by
+// line, and reporting the outcome.
+func streamScript(ctx context.Context, cmd command, overrideArgs []string, ch chan tea.Msg) {
+	start := time.Now()
+
+	if _, err := os.Stat(cmd.script); os.IsNotExist(err) {
+		ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Script not found: %s", cmd.script), ts: time.Now()}
+		if embeddedProjectRoot != "" {
+			ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Embedded project root: %s", embeddedProjectRoot), ts: time.Now()}
+		}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+	os.Chmod(cmd.script, 0755)
+
+	args := cmd.args
+	if overrideArgs != nil {
+		args = overrideArgs
+	}
+
+	backend, err := detectShellBackend(exec.LookPath)
+	if err != nil {
+		ch <- logLineMsg{stream: "stderr", text: err.Error(), ts: time.Now()}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+
+	execCmd := backend.BuildCommand(ctx, cmd.script, args, filepath.Dir(cmd.script))
+	if len(cmd.env) > 0 {
+		execCmd.Env = os.Environ()
+		for k, v := range cmd.env {
+			execCmd.Env = append(execCmd.Env, k+"="+v)
+		}
+	}
+	// Ctrl+C cancels ctx, which asks the process to shut down the same way a
+	// terminal's Ctrl+C would (SIGINT) before the runtime resorts to SIGKILL
+	// after gracePeriod.
+	execCmd.Cancel = func() error {
+		return execCmd.Process.Signal(syscall.SIGINT)
+	}
+	execCmd.WaitDelay = gracePeriod
+
+	if usePTY() {
+		streamScriptPTY(ctx, execCmd, start, ch)
+		return
+	}
+
+	stdout, err := execCmd.StdoutPipe()
+	if err != nil {
+		ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Failed to attach stdout: %s", err), ts: time.Now()}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+	stderr, err := execCmd.StderrPipe()
+	if err != nil {
+		ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Failed to attach stderr: %s", err), ts: time.Now()}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+
+	if err := execCmd.Start(); err != nil {
+		ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Failed to start script: %s", err), ts: time.Now()}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go pipeLines(&wg, stdout, "stdout", ch)
+	go pipeLines(&wg, stderr, "stderr", ch)
+	wg.Wait()
+
+	err = execCmd.Wait()
+	exitCode := 0
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else {
+			exitCode = -1
+		}
+	}
+	exitCode = backend.ResolveExitCode(cmd.script, exitCode)
+
+	ch <- finishMsg{
+		exitCode:  exitCode,
+		duration:  time.Since(start),
+		cancelled: ctx.Err() != nil,
+	}
+}
+
+// pipeLines scans r line by line (bufio.Scanner buffers partial lines
+// internally until a newline or EOF arrives), forwarding each complete line
+// to ch tagged with stream.
+func pipeLines(wg *sync.WaitGroup, r io.Reader, stream string, ch chan tea.Msg) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		ch <- logLineMsg{stream: stream, text: scanner.Text(), ts: time.Now()}
+	}
+}
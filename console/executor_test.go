@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// drainLog collects every logLineMsg from ch into lines until finishMsg
+// arrives, which it returns alongside them.
+func drainLog(t *testing.T, ch chan tea.Msg) ([]logLine, finishMsg) {
+	t.Helper()
+	var lines []logLine
+	for {
+		select {
+		case msg := <-ch:
+			switch m := msg.(type) {
+			case logLineMsg:
+				lines = append(lines, logLine(m))
+			case finishMsg:
+				return lines, m
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for script to finish")
+		}
+	}
+}
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(path, []byte(body), 0755); err != nil {
+		t.Fatalf("writing script: %v", err)
+	}
+	return path
+}
+
+func TestStreamScriptCapturesStdoutAndStderr(t *testing.T) {
+	script := writeScript(t, "#!/bin/bash\necho out-line\necho err-line >&2\nexit 3\n")
+	ch := make(chan tea.Msg, 256)
+	streamScript(context.Background(), command{script: script}, nil, ch)
+
+	lines, finish := drainLog(t, ch)
+	if finish.exitCode != 3 {
+		t.Errorf("exitCode = %d, want 3", finish.exitCode)
+	}
+	var sawStdout, sawStderr bool
+	for _, l := range lines {
+		if l.stream == "stdout" && l.text == "out-line" {
+			sawStdout = true
+		}
+		if l.stream == "stderr" && l.text == "err-line" {
+			sawStderr = true
+		}
+	}
+	if !sawStdout || !sawStderr {
+		t.Errorf("expected both stdout and stderr lines, got %+v", lines)
+	}
+}
+
+func TestStreamScriptMissingScript(t *testing.T) {
+	ch := make(chan tea.Msg, 256)
+	streamScript(context.Background(), command{script: "/no/such/script.sh"}, nil, ch)
+
+	lines, finish := drainLog(t, ch)
+	if finish.exitCode != -1 {
+		t.Errorf("exitCode = %d, want -1 for a missing script", finish.exitCode)
+	}
+	if len(lines) == 0 || !strings.Contains(lines[0].text, "Script not found") {
+		t.Errorf("expected a 'Script not found' line, got %+v", lines)
+	}
+}
+
+func TestStreamScriptCancellationSendsSigint(t *testing.T) {
+	script := writeScript(t, "#!/bin/bash\ntrap 'echo trapped; exit 7' INT\nfor i in $(seq 1 50); do sleep 0.1; done\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan tea.Msg, 256)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		streamScript(ctx, command{script: script}, nil, ch)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	lines, finish := drainLog(t, ch)
+	wg.Wait()
+	if !finish.cancelled {
+		t.Error("expected finishMsg.cancelled to be true after context cancellation")
+	}
+	if finish.duration > 4*time.Second {
+		t.Errorf("expected the script to exit quickly after SIGINT, took %s", finish.duration)
+	}
+	var sawTrap bool
+	for _, l := range lines {
+		if l.text == "trapped" {
+			sawTrap = true
+		}
+	}
+	if !sawTrap {
+		t.Errorf("expected the script's INT trap to fire before exit, got %+v", lines)
+	}
+}
+
+func TestPipeLinesBuffersPartialLines(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+	ch := make(chan tea.Msg, 16)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go pipeLines(&wg, r, "stdout", ch)
+
+	w.WriteString("partial")
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case msg := <-ch:
+		t.Fatalf("expected no line before a newline arrives, got %+v", msg)
+	default:
+	}
+
+	w.WriteString(" line\n")
+	w.Close()
+	wg.Wait()
+
+	msg := (<-ch).(logLineMsg)
+	if msg.text != "partial line" {
+		t.Errorf("text = %q, want %q", msg.text, "partial line")
+	}
+}
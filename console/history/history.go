@@ -0,0 +1,273 @@
+// Package history persists a per-project record of commands run from the
+// OpenEMR on EKS console, so the TUI can show a "Recent" shortlist and
+// support re-running the last command without retyping its prompt values.
+// It also keeps a separate, append-only audit log (AuditEntry/AppendAudit)
+// of every invocation with enough detail — resolved script path, platform,
+// project root, shell backend, transcript location — to reconstruct what
+// was run against a cluster after the fact.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry records a single completed command execution.
+type Entry struct {
+	Category   string    `json:"category"`
+	Title      string    `json:"title"`
+	Args       []string  `json:"args"`
+	Values     []string  `json:"values"`
+	Timestamp  time.Time `json:"timestamp"`
+	ExitCode   int       `json:"exitCode"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// maxEntries bounds how many entries Append keeps on disk, so the file
+// doesn't grow without limit over a long-lived project.
+const maxEntries = 500
+
+// lockSuffix names the sidecar lock file used to serialize concurrent
+// writers across separate TUI processes.
+const lockSuffix = ".lock"
+
+// DefaultPath resolves the history file location following the XDG base
+// directory spec: $XDG_STATE_HOME/openemr-on-eks/history.json, falling back
+// to ~/.local/state/openemr-on-eks/history.json when the variable is unset.
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolving home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "openemr-on-eks", "history.json"), nil
+}
+
+// Load reads and decodes the history file at path. A missing file is not an
+// error — it simply yields an empty history, as on first run. A corrupt
+// file is treated the same way rather than failing startup, since losing
+// history is far less disruptive than the TUI refusing to launch.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading history file: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		// Corruption recovery: start fresh rather than blocking the TUI.
+		return nil, nil
+	}
+	return entries, nil
+}
+
+// Append adds entry to the history file at path, creating the file and its
+// parent directory if needed, then prunes to maxEntries. A lock file next
+// to path serializes concurrent appends from multiple TUI instances.
+func Append(path string, entry Entry) error {
+	unlock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	entries = prune(entries, maxEntries)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing history file: %w", err)
+	}
+	return nil
+}
+
+// Prune truncates the history file at path to at most max entries,
+// discarding the oldest first.
+func Prune(path string, max int) error {
+	unlock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+	entries = prune(entries, max)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func prune(entries []Entry, max int) []Entry {
+	if max <= 0 || len(entries) <= max {
+		return entries
+	}
+	return entries[len(entries)-max:]
+}
+
+// Recent returns up to n distinct commands (deduped by category+title) from
+// entries in most-recently-used order, newest first.
+func Recent(entries []Entry, n int) []Entry {
+	seen := make(map[string]bool)
+	var recent []Entry
+	for i := len(entries) - 1; i >= 0 && len(recent) < n; i-- {
+		e := entries[i]
+		key := e.Category + "\x00" + e.Title
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		recent = append(recent, e)
+	}
+	return recent
+}
+
+// acquireLock creates path+".lock" exclusively, retrying briefly if another
+// process holds it, and returns a function that releases the lock. This is
+// a simple cooperative lock (not a true OS file lock) but is sufficient to
+// serialize the TUI's own read-modify-write cycle across instances.
+func acquireLock(path string) (func(), error) {
+	lockPath := path + lockSuffix
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("acquiring history lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			// Stale lock from a crashed process: steal it rather than
+			// blocking the user indefinitely.
+			os.Remove(lockPath)
+			continue
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// AuditEntry records one command invocation in full enough detail to
+// reconstruct what was done to a cluster: not just what was run (Category,
+// Title, Args) but where from (ScriptPath, ProjectRoot), how (Platform,
+// ShellBackend), when (StartTime, EndTime), with what result (ExitCode),
+// and where its captured output lives (TranscriptPath, empty if the run
+// produced no output or the transcript couldn't be saved).
+type AuditEntry struct {
+	Category       string    `json:"category"`
+	Title          string    `json:"title"`
+	ScriptPath     string    `json:"scriptPath"`
+	Args           []string  `json:"args"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	ExitCode       int       `json:"exitCode"`
+	Platform       string    `json:"platform"`
+	ProjectRoot    string    `json:"projectRoot"`
+	ShellBackend   string    `json:"shellBackend"`
+	TranscriptPath string    `json:"transcriptPath,omitempty"`
+}
+
+// AuditLogPath returns ~/.openemr-eks-console/history.jsonl, creating its
+// parent directory if needed. Unlike DefaultPath's XDG-based history.json
+// (a small, pruned shortlist used for the "Recent" menu and field-history
+// cycling), this file is append-only and never pruned, since it is meant to
+// serve as an audit trail for HIPAA-audited deployments rather than a UI
+// convenience cache.
+func AuditLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".openemr-eks-console")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// AppendAudit appends entry to the JSONL audit log at path, one JSON object
+// per line, creating the file if it doesn't exist yet. It shares
+// acquireLock with Append so a concurrent writer never interleaves partial
+// lines from two TUI instances.
+func AppendAudit(path string, entry AuditEntry) error {
+	unlock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// LoadAudit reads and decodes the JSONL audit log at path, oldest first. A
+// missing file yields an empty log rather than an error, as on first run. A
+// line that fails to decode is skipped rather than failing the whole load,
+// since a truncated last line (e.g. from a process killed mid-write) should
+// not hide every entry before it.
+func LoadAudit(path string) ([]AuditEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	var entries []AuditEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
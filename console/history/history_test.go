@@ -0,0 +1,161 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFileReturnsEmpty(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "nope", "history.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries != nil {
+		t.Errorf("expected nil entries, got %v", entries)
+	}
+}
+
+func TestAppendAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	e := Entry{Category: "Deployment", Title: "Quick Deploy", ExitCode: 0, Timestamp: time.Unix(1000, 0)}
+	if err := Append(path, e); err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Title != "Quick Deploy" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestPruneTruncatesToMax(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	for i := 0; i < 5; i++ {
+		if err := Append(path, Entry{Title: "cmd"}); err != nil {
+			t.Fatalf("append %d failed: %v", i, err)
+		}
+	}
+	if err := Prune(path, 3); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries after prune, got %d", len(entries))
+	}
+}
+
+func TestLoadCorruptFileRecovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	if err := os.WriteFile(path, []byte("{not valid json"), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected corruption to be recovered silently, got error: %v", err)
+	}
+	if entries != nil {
+		t.Errorf("expected nil entries for corrupt file, got %v", entries)
+	}
+}
+
+func TestRecentOrderingAndDedup(t *testing.T) {
+	entries := []Entry{
+		{Category: "A", Title: "One", Timestamp: time.Unix(1, 0)},
+		{Category: "A", Title: "Two", Timestamp: time.Unix(2, 0)},
+		{Category: "A", Title: "One", Timestamp: time.Unix(3, 0)}, // re-run, should dedup to this position
+	}
+	recent := Recent(entries, 5)
+	if len(recent) != 2 {
+		t.Fatalf("expected 2 distinct entries, got %d", len(recent))
+	}
+	if recent[0].Title != "One" || recent[0].Timestamp != time.Unix(3, 0) {
+		t.Errorf("expected most recent run of 'One' first, got %+v", recent[0])
+	}
+	if recent[1].Title != "Two" {
+		t.Errorf("expected 'Two' second, got %+v", recent[1])
+	}
+}
+
+func TestRecentRespectsLimit(t *testing.T) {
+	entries := []Entry{
+		{Category: "A", Title: "One"},
+		{Category: "A", Title: "Two"},
+		{Category: "A", Title: "Three"},
+	}
+	recent := Recent(entries, 2)
+	if len(recent) != 2 {
+		t.Errorf("expected limit of 2, got %d", len(recent))
+	}
+}
+
+func TestAppendAuditAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	e1 := AuditEntry{Title: "Quick Deploy", ExitCode: 0, Platform: "linux"}
+	e2 := AuditEntry{Title: "Check Deployment Health", ExitCode: 1, Platform: "linux"}
+	if err := AppendAudit(path, e1); err != nil {
+		t.Fatalf("append 1 failed: %v", err)
+	}
+	if err := AppendAudit(path, e2); err != nil {
+		t.Fatalf("append 2 failed: %v", err)
+	}
+
+	entries, err := LoadAudit(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Title != "Quick Deploy" || entries[1].Title != "Check Deployment Health" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestLoadAuditMissingFileReturnsEmpty(t *testing.T) {
+	entries, err := LoadAudit(filepath.Join(t.TempDir(), "nope", "history.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries != nil {
+		t.Errorf("expected nil entries, got %v", entries)
+	}
+}
+
+func TestLoadAuditSkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"title":"One"}` + "\n" + "not json" + "\n" + `{"title":"Two"}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	entries, err := LoadAudit(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Title != "One" || entries[1].Title != "Two" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestAuditLogPathUnderConsoleDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path, err := AuditLogPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := filepath.Join(home, ".openemr-eks-console", "history.jsonl")
+	if path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+}
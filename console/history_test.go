@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openemr/openemr-on-eks/console/history"
+)
+
+func TestBuildRecentCategoryEmptyHistory(t *testing.T) {
+	if cat := buildRecentCategory(testCategories(), nil); cat != nil {
+		t.Errorf("expected nil category for empty history, got %+v", cat)
+	}
+}
+
+func TestBuildRecentCategoryResolvesCommands(t *testing.T) {
+	entries := []history.Entry{
+		{Category: "Alpha", Title: "Cmd1", Timestamp: time.Unix(1, 0)},
+	}
+	cat := buildRecentCategory(testCategories(), entries)
+	if cat == nil {
+		t.Fatal("expected a Recent category")
+	}
+	if cat.name != recentCategoryName {
+		t.Errorf("expected category name %q, got %q", recentCategoryName, cat.name)
+	}
+	if len(cat.commands) != 1 || cat.commands[0].title != "Cmd1" {
+		t.Errorf("unexpected commands: %+v", cat.commands)
+	}
+}
+
+func TestBuildRecentCategorySkipsUnknownCommands(t *testing.T) {
+	entries := []history.Entry{
+		{Category: "Ghost", Title: "Nonexistent", Timestamp: time.Unix(1, 0)},
+	}
+	if cat := buildRecentCategory(testCategories(), entries); cat != nil {
+		t.Errorf("expected nil category when no entries resolve, got %+v", cat)
+	}
+}
+
+func TestFindCommandSkipsRecentCategory(t *testing.T) {
+	cats := append([]category{{name: recentCategoryName, commands: []command{{title: "Cmd1"}}}}, testCategories()...)
+	m := model{categories: cats}
+	ci, _, ok := m.findCommand("Alpha", "Cmd1")
+	if !ok {
+		t.Fatal("expected to find Cmd1 in Alpha")
+	}
+	if cats[ci].name != "Alpha" {
+		t.Errorf("expected match in Alpha category, got %q", cats[ci].name)
+	}
+}
+
+func TestRerunLastNoHistoryNoOp(t *testing.T) {
+	m := testModel()
+	updated, _ := m.Update(keyMsg("r"))
+	m2 := updated.(model)
+	if m2.executing {
+		t.Error("'r' with no history should be a no-op")
+	}
+}
+
+func TestRerunLastExecutesPriorCommand(t *testing.T) {
+	m := testModel()
+	m.history = []history.Entry{{Category: "Alpha", Title: "Cmd1", Args: []string{"--flag"}}}
+	updated, _ := m.Update(keyMsg("r"))
+	m2 := updated.(model)
+	if !m2.executing {
+		t.Error("'r' with history should start executing the last command")
+	}
+	if len(m2.execArgs) != 1 || m2.execArgs[0] != "--flag" {
+		t.Errorf("expected last command's args to be reused, got %v", m2.execArgs)
+	}
+}
+
+func TestRecordHistoryNoOpWithoutHistoryPath(t *testing.T) {
+	m := testModel()
+	m.historyPath = ""
+	m.selected = m.cursor
+	m.execStart = time.Now()
+	m.recordHistory(0)
+	// historyPath is empty, so recordHistory should be a no-op.
+	if len(m.history) != 0 {
+		t.Errorf("expected no history recorded without a historyPath, got %d entries", len(m.history))
+	}
+}
+
+func TestCategoryNameForRecentResolvesOriginal(t *testing.T) {
+	cats := testCategories()
+	recentCat := category{name: recentCategoryName, commands: []command{cats[0].commands[0]}}
+	all := append([]category{recentCat}, cats...)
+	if got := categoryNameFor(all, 0); got != "Alpha" {
+		t.Errorf("expected Recent entry to resolve to 'Alpha', got %q", got)
+	}
+}
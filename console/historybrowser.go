@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/openemr/openemr-on-eks/console/history"
+)
+
+// historyBrowserState holds an in-progress "past runs" browsing session
+// opened with 'h'. entries is newest-first (the reverse of the audit log's
+// on-disk order) so the most recent run is always at the top.
+type historyBrowserState struct {
+	entries []history.AuditEntry
+	cursor  int
+
+	diffPivot int    // index into entries marked by the first 'd' press, or -1 if none
+	diffText  string // rendered diff output, or "" if no diff has been computed yet
+	message   string // transient status line, e.g. a re-run or diff error
+}
+
+// newHistoryBrowserState opens a browsing session over entries (as loaded
+// from the audit log, oldest first), newest first.
+func newHistoryBrowserState(entries []history.AuditEntry) *historyBrowserState {
+	reversed := make([]history.AuditEntry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	return &historyBrowserState{entries: reversed, diffPivot: -1}
+}
+
+// updateHistoryBrowser handles keypresses while the history browser is open:
+// navigation, re-running the selected entry, diffing two entries' saved
+// transcripts, and closing back to the menu.
+func (m model) updateHistoryBrowser(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	hb := m.historyBrowser
+
+	switch key.Code {
+	case 'c':
+		if key.Mod == tea.ModCtrl {
+			m.quitting = true
+			return m, tea.Quit
+		}
+	case tea.KeyEscape, 'q':
+		if hb.diffText != "" {
+			hb.diffText = ""
+			return m, nil
+		}
+		m.historyBrowser = nil
+		return m, nil
+	case tea.KeyUp, 'k':
+		if hb.cursor > 0 {
+			hb.cursor--
+		}
+	case tea.KeyDown, 'j':
+		if hb.cursor < len(hb.entries)-1 {
+			hb.cursor++
+		}
+	case tea.KeyEnter:
+		if len(hb.entries) == 0 {
+			return m, nil
+		}
+		e := hb.entries[hb.cursor]
+		ci, cj, ok := m.findCommand(e.Category, e.Title)
+		if !ok {
+			hb.message = fmt.Sprintf("%q is no longer in the catalog", e.Title)
+			return m, nil
+		}
+		cmd := m.categories[ci].commands[cj]
+		for i, fe := range m.flatIndex {
+			if !fe.isCategory && fe.catIdx == ci && fe.cmdIdx == cj {
+				m.cursor = i
+				break
+			}
+		}
+		m.historyBrowser = nil
+		return m.startExecution(cmd, e.Args, nil)
+	case 'd':
+		if len(hb.entries) == 0 {
+			return m, nil
+		}
+		if hb.diffPivot < 0 {
+			hb.diffPivot = hb.cursor
+			hb.diffText = ""
+			hb.message = "Select a second run to diff against " + hb.entries[hb.cursor].Title
+			return m, nil
+		}
+		text, err := diffAuditTranscripts(hb.entries[hb.diffPivot], hb.entries[hb.cursor])
+		if err != nil {
+			hb.message = fmt.Sprintf("diff failed: %s", err)
+		} else {
+			hb.diffText = text
+			hb.message = ""
+		}
+		hb.diffPivot = -1
+		return m, nil
+	}
+	return m, nil
+}
+
+// diffAuditTranscripts reads a and b's saved transcripts (as recorded in
+// their TranscriptPath) and returns a unified line diff. Either side missing
+// a transcript is reported as an error rather than silently diffing nothing,
+// since a caller asking to compare two runs should know when one has no
+// captured output to compare.
+func diffAuditTranscripts(a, b history.AuditEntry) (string, error) {
+	if a.TranscriptPath == "" || b.TranscriptPath == "" {
+		return "", fmt.Errorf("one or both runs have no saved transcript")
+	}
+	aData, err := os.ReadFile(a.TranscriptPath)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", a.TranscriptPath, err)
+	}
+	bData, err := os.ReadFile(b.TranscriptPath)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", b.TranscriptPath, err)
+	}
+
+	header := fmt.Sprintf("--- %s (%s)\n+++ %s (%s)\n",
+		a.Title, a.StartTime.Format("2006-01-02 15:04:05"),
+		b.Title, b.StartTime.Format("2006-01-02 15:04:05"))
+	return header + diffLines(splitLines(string(aData)), splitLines(string(bData))), nil
+}
+
+// splitLines splits s on newlines, dropping a single trailing empty element
+// left by a file that (as saveTranscript's output does) ends in "\n".
+func splitLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a unified-style line diff of a and b: unchanged lines
+// prefixed "  ", removed lines (from a) prefixed "- ", added lines (from b)
+// prefixed "+ ". It uses a straightforward longest-common-subsequence
+// backtrace rather than a full Myers diff, which is simpler and more than
+// fast enough for the size of a single command's captured output.
+func diffLines(a, b []string) string {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out.WriteString("- " + a[i] + "\n")
+	}
+	for ; j < m; j++ {
+		out.WriteString("+ " + b[j] + "\n")
+	}
+	return out.String()
+}
+
+// historyViewportLines caps how many history entries are listed; entries
+// past it are not shown, and the diff view prints every diff line.
+const historyViewportLines = 15
+
+// viewHistoryBrowser renders the history browser overlay: a list of past
+// runs (or, once two are picked with 'd', the diff between their
+// transcripts).
+func (m model) viewHistoryBrowser() string {
+	hb := m.historyBrowser
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render("Run History"))
+	v.WriteString("\n\n")
+
+	if hb.diffText != "" {
+		v.WriteString(descStyle.Render(hb.diffText))
+		v.WriteString("\n")
+		v.WriteString(helpStyle.Render("Esc: Back to list"))
+		return v.String()
+	}
+
+	if len(hb.entries) == 0 {
+		v.WriteString(descStyle.Render("No runs recorded yet"))
+		v.WriteString("\n\n")
+	}
+
+	for i, e := range hb.entries {
+		if i >= historyViewportLines {
+			break
+		}
+		line := fmt.Sprintf("%-30s %s  exit=%d  %s", e.Title, e.StartTime.Format("2006-01-02 15:04:05"), e.ExitCode, e.Platform)
+		prefix := "  "
+		if i == hb.diffPivot {
+			prefix = "◆ "
+		}
+		if i == hb.cursor {
+			v.WriteString(selectedStyle.Render(prefix + line))
+		} else {
+			v.WriteString(itemStyle.Render(prefix + line))
+		}
+		v.WriteString("\n")
+	}
+
+	if hb.message != "" {
+		v.WriteString("\n")
+		v.WriteString(descStyle.Render(hb.message))
+	}
+
+	v.WriteString("\n")
+	v.WriteString(helpStyle.Render("↑/↓: Navigate  Enter: Re-run  d: Diff two runs' transcripts  Esc: Close"))
+	return v.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openemr/openemr-on-eks/console/history"
+)
+
+func TestNewHistoryBrowserStateReversesToNewestFirst(t *testing.T) {
+	entries := []history.AuditEntry{
+		{Title: "First", StartTime: time.Unix(1, 0)},
+		{Title: "Second", StartTime: time.Unix(2, 0)},
+	}
+	hb := newHistoryBrowserState(entries)
+	if len(hb.entries) != 2 || hb.entries[0].Title != "Second" || hb.entries[1].Title != "First" {
+		t.Errorf("unexpected order: %+v", hb.entries)
+	}
+	if hb.diffPivot != -1 {
+		t.Errorf("expected diffPivot -1 initially, got %d", hb.diffPivot)
+	}
+}
+
+func TestUpdateHistoryBrowserNavigatesAndCloses(t *testing.T) {
+	m := testModel()
+	m.historyBrowser = newHistoryBrowserState([]history.AuditEntry{
+		{Title: "First"}, {Title: "Second"},
+	})
+
+	m2, _ := m.Update(keyMsg("down"))
+	mm := m2.(model)
+	if mm.historyBrowser.cursor != 1 {
+		t.Errorf("expected cursor 1 after down, got %d", mm.historyBrowser.cursor)
+	}
+
+	m3, _ := mm.Update(keyMsg("esc"))
+	mm3 := m3.(model)
+	if mm3.historyBrowser != nil {
+		t.Error("expected Esc to close the history browser")
+	}
+}
+
+func TestUpdateHistoryBrowserEnterRerunsMatchingCommand(t *testing.T) {
+	m := testModel()
+	m.historyBrowser = newHistoryBrowserState([]history.AuditEntry{
+		{Category: "Alpha", Title: "Cmd1", Args: []string{"--x"}},
+	})
+
+	m2, _ := m.Update(keyMsg("enter"))
+	mm := m2.(model)
+	if mm.historyBrowser != nil {
+		t.Error("expected the history browser to close after re-running")
+	}
+	if !mm.executing {
+		t.Error("expected re-run to start execution")
+	}
+}
+
+func TestUpdateHistoryBrowserEnterUnknownCommandReportsMessage(t *testing.T) {
+	m := testModel()
+	m.historyBrowser = newHistoryBrowserState([]history.AuditEntry{
+		{Category: "Ghost", Title: "Nonexistent"},
+	})
+
+	m2, _ := m.Update(keyMsg("enter"))
+	mm := m2.(model)
+	if mm.historyBrowser == nil || mm.historyBrowser.message == "" {
+		t.Error("expected a message explaining the command could not be found")
+	}
+}
+
+func TestDiffLinesMarksAddedRemovedAndUnchanged(t *testing.T) {
+	a := []string{"same", "removed", "same2"}
+	b := []string{"same", "added", "same2"}
+	got := diffLines(a, b)
+	want := "  same\n- removed\n+ added\n  same2\n"
+	if got != want {
+		t.Errorf("diffLines = %q, want %q", got, want)
+	}
+}
+
+func TestDiffAuditTranscriptsRequiresBothPaths(t *testing.T) {
+	_, err := diffAuditTranscripts(history.AuditEntry{}, history.AuditEntry{TranscriptPath: "/tmp/x"})
+	if err == nil {
+		t.Error("expected an error when one side has no transcript")
+	}
+}
+
+func TestDiffAuditTranscriptsReadsAndDiffsFiles(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.log")
+	bPath := filepath.Join(dir, "b.log")
+	if err := os.WriteFile(aPath, []byte("ok\nfail\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bPath, []byte("ok\npass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := diffAuditTranscripts(
+		history.AuditEntry{Title: "Run A", TranscriptPath: aPath},
+		history.AuditEntry{Title: "Run B", TranscriptPath: bPath},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "- fail") || !strings.Contains(text, "+ pass") {
+		t.Errorf("expected diff markers for the changed line, got %q", text)
+	}
+}
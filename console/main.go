@@ -1,40 +1,55 @@
 // Package main implements a Terminal User Interface (TUI) console for managing
 // OpenEMR on EKS deployments. The console provides an interactive menu for
 // executing deployment scripts, validating infrastructure, and managing backups.
-//
-// Platform Support:
-//   - macOS: Uses osascript to open new Terminal windows
-//   - Windows: Uses PowerShell to open new PowerShell windows with bash script execution
-//   - Linux: Not supported
+// Scripts run as bash subprocesses, via whichever ShellBackend is detected
+// for the host (Git Bash, WSL, PowerShell, or plain cmd.exe on Windows;
+// bash, zsh, or fish on macOS/Linux, honoring $SHELL; or Nushell if
+// OPENEMR_EKS_SHELL=nu is forced), with output streamed live into the
+// console's own log pane rather than in a separate terminal window (except
+// for commands whose mode is new-window, which open in an external terminal).
 //
 // The console detects the project root directory at startup using:
-//   1. OPENEMR_EKS_PROJECT_ROOT environment variable (highest priority, allows override)
-//   2. Embedded project root path (set at build time via -ldflags)
+//  1. OPENEMR_EKS_PROJECT_ROOT environment variable (highest priority, allows override)
+//  2. Embedded project root path (set at build time via -ldflags)
 //
 // If the project is moved after building, users can set the environment variable
 // to point to the new location without rebuilding.
 package main
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/openemr/openemr-on-eks/console/ansi"
+	"github.com/openemr/openemr-on-eks/console/history"
 )
 
+// recentCategoryName marks the synthetic "Recent" pseudo-category injected
+// at the top of the menu when history is available.
+const recentCategoryName = "Recent"
+
+// historyLimit caps how many recently-used commands are shown.
+const historyLimit = 5
+
+// version is the console's display version, shown in the title bar.
+const version = "0.2.0"
+
 // embeddedProjectRoot is set at build time using -ldflags during compilation.
 // This allows the binary to remember where it was built from, enabling it to
 // locate project scripts and resources even when run from a different directory.
 //
 // Example build command:
-//   go build -ldflags "-X main.embeddedProjectRoot=$PWD" -o openemr-eks-console
+//
+//	go build -ldflags "-X main.embeddedProjectRoot=$PWD" -o openemr-eks-console
 //
 // Users can override this at runtime by setting the OPENEMR_EKS_PROJECT_ROOT
 // environment variable, which takes precedence over the embedded path.
@@ -51,6 +66,12 @@ var (
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("205"))
 
+	// categoryStyle: Bold cyan text for category headers in the menu
+	categoryStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("86")).
+			Bold(true).
+			PaddingLeft(1)
+
 	// itemStyle: Light gray text for unselected menu items
 	itemStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("252")).
@@ -79,15 +100,364 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241")).
 			PaddingTop(1)
+
+	// dangerStyle: Bold red text for destructive-command warnings
+	dangerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	// matchStyle: Bold underlined text marking fuzzy-matched runes
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true).
+			Underline(true)
 )
 
+// inputField describes a single prompt collected from the user before a
+// command runs, e.g. a bucket name or snapshot ID passed through to the
+// underlying script as a positional argument or a flag value.
+type inputField struct {
+	label       string          // Display label shown above the input box
+	placeholder string          // Grey placeholder text shown when the field is empty
+	required    bool            // Whether submit is blocked while this field is empty
+	flag        string          // If set, the value is passed as "--flag value" instead of positional
+	emptyAdds   string          // If set, appended as a bare arg when the (optional) value is left empty
+	sensitive   bool            // If set, the submitted value is redacted before being written to history
+	validator   *fieldValidator // Optional check beyond "required"; nil means none
+}
+
 // command represents a single executable command in the console menu.
 // Each command has a display title, description, script path, and optional arguments.
 type command struct {
-	title       string   // Display name shown in the menu
-	description string   // Help text explaining what the command does
-	script      string   // Full path to the bash script to execute
-	args        []string // Command-line arguments to pass to the script
+	title       string            // Display name shown in the menu
+	description string            // Help text explaining what the command does
+	script      string            // Full path to the bash script to execute
+	args        []string          // Command-line arguments to pass to the script
+	destructive bool              // Whether the command requires a confirmation prompt
+	prompts     []inputField      // Prompts collected from the user before execution
+	env         map[string]string // Extra environment variables set on the script's process
+	tags        []string          // Free-form labels for grouping/filtering in a site-specific catalog
+	execMode    executeMode       // How the script is run: inline (default) or in a spawned terminal window
+}
+
+// executeMode selects how a command's script is run. The zero value,
+// modeInline, is the default for catalog entries that don't set one: stream
+// output straight into the TUI's own executing view. modeNewWindow instead
+// hands the script off to runInExternalTerminal, for scripts that need a
+// real interactive TTY (a sudo or MFA prompt) that an inline, piped
+// exec.Command can't provide.
+type executeMode int
+
+const (
+	modeInline executeMode = iota
+	modeNewWindow
+)
+
+// parseExecMode converts a catalog.Command's Mode string ("", "inline", or
+// "new-window" — catalog.Validate already rejects anything else) into an
+// executeMode, defaulting to modeInline.
+func parseExecMode(s string) executeMode {
+	if s == "new-window" {
+		return modeNewWindow
+	}
+	return modeInline
+}
+
+// catalogString is the inverse of parseExecMode, for toCatalogFile. Inline
+// round-trips as "" rather than "inline" so a dumped catalog only mentions
+// mode for the commands that actually need it.
+func (e executeMode) catalogString() string {
+	if e == modeNewWindow {
+		return "new-window"
+	}
+	return ""
+}
+
+// category groups related commands under a single labeled section of the menu.
+type category struct {
+	name     string
+	icon     string
+	commands []command
+}
+
+// flatEntry is a single row in the menu's flattened display order: either a
+// category header or one of that category's commands. Keeping a flat index
+// alongside the nested categories lets cursor movement, search, and rendering
+// all walk a single linear list instead of tracking nested indices by hand.
+type flatEntry struct {
+	isCategory bool
+	catIdx     int
+	cmdIdx     int // valid only when isCategory is false
+}
+
+// buildRecentCategory builds a synthetic "Recent" category from the last
+// historyLimit distinct commands in entries, resolving each back to its
+// real command definition in cats so execution behaves identically to
+// selecting it from its normal category. Returns nil when there is no
+// history to show yet.
+func buildRecentCategory(cats []category, entries []history.Entry) *category {
+	recent := history.Recent(entries, historyLimit)
+	if len(recent) == 0 {
+		return nil
+	}
+
+	cat := category{name: recentCategoryName, icon: "🕘"}
+	for _, e := range recent {
+		for _, c := range cats {
+			if c.name != e.Category {
+				continue
+			}
+			for _, cmd := range c.commands {
+				if cmd.title == e.Title {
+					cat.commands = append(cat.commands, cmd)
+				}
+			}
+		}
+	}
+	if len(cat.commands) == 0 {
+		return nil
+	}
+	return &cat
+}
+
+// findCommand locates a command by category and title among the model's
+// "real" categories (skipping the synthetic Recent bucket, which only ever
+// mirrors entries that already exist elsewhere).
+func (m *model) findCommand(categoryName, title string) (catIdx, cmdIdx int, ok bool) {
+	for ci, cat := range m.categories {
+		if cat.name == recentCategoryName || cat.name != categoryName {
+			continue
+		}
+		for cj, cmd := range cat.commands {
+			if cmd.title == title {
+				return ci, cj, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// buildFlatIndex flattens a category tree into display order: each category
+// header is followed immediately by its commands.
+func buildFlatIndex(cats []category) []flatEntry {
+	var flat []flatEntry
+	for ci, cat := range cats {
+		flat = append(flat, flatEntry{isCategory: true, catIdx: ci})
+		for cj := range cat.commands {
+			flat = append(flat, flatEntry{isCategory: false, catIdx: ci, cmdIdx: cj})
+		}
+	}
+	return flat
+}
+
+// inputState tracks an in-progress multi-field prompt form shown before a
+// command with prompts runs.
+type inputState struct {
+	fields    []inputField
+	values    []string
+	active    int  // index of the field currently being edited
+	cursor    int  // rune cursor position within the active field's value
+	attempted bool // set once the user tries to submit with invalid fields
+
+	fieldHistory [][]string // per-field prior submitted values, newest first; nil entries mean none recorded
+	histPos      []int      // per-field position into fieldHistory; -1 means editing the live value
+	liveValue    []string   // per-field value being typed before Up first browsed into history
+}
+
+// newInputState creates a blank inputState for the given fields, one empty
+// value slot per field, starting on the first field. Enum fields (rendered
+// as a select) start on their first choice rather than empty text.
+func newInputState(fields []inputField) *inputState {
+	return newInputStateWithHistory(fields, nil)
+}
+
+// newInputStateWithHistory is newInputState plus per-field prior values
+// (newest first) the user can cycle through with Up/Down while editing that
+// field. fieldHistory may be nil or shorter than fields; missing entries
+// simply have no history to cycle through.
+func newInputStateWithHistory(fields []inputField, fieldHistory [][]string) *inputState {
+	values := make([]string, len(fields))
+	histPos := make([]int, len(fields))
+	for i, f := range fields {
+		histPos[i] = -1
+		if f.validator != nil && f.validator.kind == "enum" && len(f.validator.choices) > 0 {
+			values[i] = f.validator.choices[0]
+		}
+	}
+	return &inputState{
+		fields:       fields,
+		values:       values,
+		fieldHistory: fieldHistory,
+		histPos:      histPos,
+		liveValue:    make([]string, len(fields)),
+	}
+}
+
+// fieldError returns the validation message for field i given its current
+// value, or "" if it currently passes. An empty value is only an error when
+// the field is required; a non-empty value is checked against the field's
+// validator, if any.
+func (inp *inputState) fieldError(i int) string {
+	f := inp.fields[i]
+	value := inp.values[i]
+	if value == "" {
+		if f.required {
+			return f.label + " is required"
+		}
+		return ""
+	}
+	if ok, reason := f.validator.validate(value); !ok {
+		return f.label + " " + reason
+	}
+	return ""
+}
+
+// editActive handles the common single-field text-editing keys (cursor
+// movement, backspace/delete, and plain character insertion) against the
+// active field, returning true if key was one of them. Callers handle
+// navigation/submission keys like Tab, Enter, and Escape themselves, since
+// those mean different things in a multi-field form versus a single-field
+// challenge prompt.
+func (inp *inputState) editActive(key tea.KeyPressMsg) bool {
+	if f := inp.fields[inp.active]; f.validator != nil && f.validator.kind == "enum" && len(f.validator.choices) > 0 {
+		switch key.Code {
+		case tea.KeyLeft:
+			inp.values[inp.active] = cycleChoice(f.validator.choices, inp.values[inp.active], -1)
+			return true
+		case tea.KeyRight:
+			inp.values[inp.active] = cycleChoice(f.validator.choices, inp.values[inp.active], 1)
+			return true
+		}
+		return false
+	}
+
+	switch key.Code {
+	case tea.KeyUp:
+		return inp.cycleHistory(1)
+	case tea.KeyDown:
+		return inp.cycleHistory(-1)
+	case tea.KeyLeft:
+		if inp.cursor > 0 {
+			inp.cursor--
+		}
+	case tea.KeyRight:
+		if inp.cursor < len([]rune(inp.values[inp.active])) {
+			inp.cursor++
+		}
+	case tea.KeyHome:
+		inp.cursor = 0
+	case tea.KeyEnd:
+		inp.cursor = len([]rune(inp.values[inp.active]))
+	case tea.KeyBackspace:
+		if inp.cursor > 0 {
+			r := []rune(inp.values[inp.active])
+			r = append(r[:inp.cursor-1], r[inp.cursor:]...)
+			inp.values[inp.active] = string(r)
+			inp.cursor--
+			inp.resetHistoryBrowse()
+		}
+	case tea.KeyDelete:
+		r := []rune(inp.values[inp.active])
+		if inp.cursor < len(r) {
+			r = append(r[:inp.cursor], r[inp.cursor+1:]...)
+			inp.values[inp.active] = string(r)
+			inp.resetHistoryBrowse()
+		}
+	default:
+		if key.Text == "" {
+			return false
+		}
+		r := []rune(inp.values[inp.active])
+		pre := append(append([]rune{}, r[:inp.cursor]...), []rune(key.Text)...)
+		inp.values[inp.active] = string(append(pre, r[inp.cursor:]...))
+		inp.cursor += len([]rune(key.Text))
+		inp.resetHistoryBrowse()
+	}
+	return true
+}
+
+// resetHistoryBrowse marks the active field as being edited live again once
+// the user types into it directly, so a subsequent Up starts cycling
+// history from the front rather than from wherever a prior browse left off.
+func (inp *inputState) resetHistoryBrowse() {
+	if inp.active < len(inp.histPos) {
+		inp.histPos[inp.active] = -1
+	}
+}
+
+// cycleHistory moves the active field's displayed value by delta steps
+// through its prior submitted values (delta>0 towards older, delta<0 back
+// towards the value being typed before history browsing started). It
+// reports whether the active field has any history to cycle through at
+// all, so callers can fall back to normal key handling when it doesn't.
+func (inp *inputState) cycleHistory(delta int) bool {
+	i := inp.active
+	if i >= len(inp.fieldHistory) || len(inp.fieldHistory[i]) == 0 {
+		return false
+	}
+	hist := inp.fieldHistory[i]
+
+	if inp.histPos[i] == -1 {
+		inp.liveValue[i] = inp.values[i]
+	}
+
+	pos := inp.histPos[i] + delta
+	if pos < -1 {
+		pos = -1
+	}
+	if pos >= len(hist) {
+		pos = len(hist) - 1
+	}
+	inp.histPos[i] = pos
+
+	if pos == -1 {
+		inp.values[i] = inp.liveValue[i]
+	} else {
+		inp.values[i] = hist[pos]
+	}
+	inp.cursor = len([]rune(inp.values[i]))
+	return true
+}
+
+// fieldIndex returns the index of f within fields, matching by label, or 0
+// if not found.
+func fieldIndex(fields []inputField, f inputField) int {
+	for i, field := range fields {
+		if field.label == f.label {
+			return i
+		}
+	}
+	return 0
+}
+
+// buildArgsFromInput appends the submitted prompt values to a command's base
+// arguments, in field order. A field with a flag name is emitted as two
+// argv entries ("--flag", value); otherwise the value is positional. An
+// empty value is skipped entirely, unless the field has an emptyAdds token
+// (e.g. restore.sh's Snapshot field sets emptyAdds to "--latest-snapshot",
+// so leaving it blank means "use the latest snapshot" instead of passing
+// nothing).
+func buildArgsFromInput(cmd command, inp *inputState) []string {
+	args := append([]string{}, cmd.args...)
+
+	for i, field := range inp.fields {
+		value := ""
+		if i < len(inp.values) {
+			value = inp.values[i]
+		}
+		if value == "" {
+			if field.emptyAdds != "" {
+				args = append(args, field.emptyAdds)
+			}
+			continue
+		}
+		if field.flag != "" {
+			args = append(args, "--"+field.flag, value)
+		} else {
+			args = append(args, value)
+		}
+	}
+	return args
 }
 
 // model represents the application state for the Bubbletea TUI framework.
@@ -96,14 +466,42 @@ type command struct {
 //   - Update() processes messages/events and returns new state
 //   - View() renders the current state to the terminal
 type model struct {
-	commands    []command // List of available commands to display
-	cursor      int       // Current cursor position in the menu (0-indexed)
-	selected    int       // Index of the command currently being executed
-	quitting    bool      // Flag indicating the user wants to exit
-	executing   bool      // Flag indicating a command is currently running
-	output      string    // Success output message from command execution
-	error       string    // Error message from command execution
-	projectRoot string    // Resolved project root directory path
+	categories  []category      // Menu contents, grouped by category
+	flatIndex   []flatEntry     // Flattened display order derived from categories
+	cmdCount    int             // Total number of commands across all categories
+	cursor      int             // Current position within flatIndex
+	selected    int             // flatIndex position of the command being executed
+	quitting    bool            // Flag indicating the user wants to exit
+	executing   bool            // Flag indicating the streaming-output pane is open (running or just finished)
+	challenge   *challengeState // Active destructive-command typing challenge, or nil
+	showHelp    bool            // Flag indicating the expanded help panel is open
+	projectRoot string          // Resolved project root directory path
+	input       *inputState     // Active prompt form, or nil when no prompts are open
+
+	search *searchState // Active fuzzy-search session, or nil outside search mode
+
+	historyPath string          // Location of the persisted history.json
+	history     []history.Entry // Loaded run history, oldest first
+
+	auditPath      string               // Location of the persisted history.jsonl audit log
+	auditLog       []history.AuditEntry // Loaded audit log, oldest first
+	historyBrowser *historyBrowserState // Active "past runs" browsing session opened with 'h', or nil
+
+	execArgs   []string  // Args passed to the command currently executing (for history)
+	execValues []string  // Submitted prompt field values for the current execution (for history), nil if no prompts
+	execStart  time.Time // When the current execution began (for duration)
+
+	execCh        chan tea.Msg       // Channel the running script streams logLineMsg/finishMsg into
+	execCancel    context.CancelFunc // Cancels the running script's context, used by Ctrl+C
+	execDone      bool               // Set once finishMsg arrives; the log pane stays open until dismissed
+	execExitCode  int                // Exit code from the last finished run
+	execCancelled bool               // Whether the last run was cancelled via Ctrl+C rather than exiting on its own
+	logLines      []logLine          // Ring buffer of captured output lines for the current/last run
+	logScroll     int                // Lines scrolled up from the tail; 0 means follow the live tail
+	spinnerFrame  int                // Animation frame for the "running" spinner
+	transcriptMsg string             // Status line set after 's' saves (or fails to save) the transcript
+
+	launchMessage string // Status line shown on the menu after launching a modeNewWindow command
 }
 
 // verifyProjectStructure validates that a directory contains all required
@@ -137,37 +535,49 @@ func convertWindowsPathToUnix(windowsPath string) string {
 		// If conversion fails, just use the original path with forward slashes
 		return strings.ReplaceAll(windowsPath, "\\", "/")
 	}
-	
+
 	// Replace backslashes with forward slashes
 	unixPath := strings.ReplaceAll(absPath, "\\", "/")
-	
+
 	// Convert drive letter to Git Bash format (C: -> /c)
 	if len(unixPath) >= 2 && unixPath[1] == ':' {
 		drive := strings.ToLower(string(unixPath[0]))
 		unixPath = "/" + drive + unixPath[2:]
 	}
-	
+
 	return unixPath
 }
 
-// initialModel initializes the TUI application model with project root detection
-// and command definitions. This function is called once at application startup.
+// parseConfigFlag pulls a leading "--config <path>" out of args, setting
+// catalogPathOverride and returning the remaining args for subcommand
+// dispatch. It's deliberately narrow (global flags only, no interspersed
+// parsing) since the console otherwise has no use for a general flag parser.
+func parseConfigFlag(args []string) []string {
+	if len(args) == 0 || args[0] != "--config" {
+		return args
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "--config requires a path argument")
+		os.Exit(1)
+	}
+	catalogPathOverride = args[1]
+	return append([]string{}, args[2:]...)
+}
+
+// resolveProjectRoot determines the project root using the same priority
+// order used by both the interactive UI and the headless CLI subcommands:
 //
-// Project Root Detection Strategy (in priority order):
-//   1. OPENEMR_EKS_PROJECT_ROOT environment variable (highest priority)
-//      - Allows users to override the embedded path if the project was moved
-//      - Useful when the binary was built in one location but the project moved
-//   2. Embedded project root (set at build time via -ldflags)
-//      - Automatically embedded during compilation by start_console.ps1 (Windows)
-//      - or Makefile (macOS)
-//   3. If neither is valid, the application exits with detailed error messages
+//  1. OPENEMR_EKS_PROJECT_ROOT environment variable (highest priority)
+//     - Allows users to override the embedded path if the project was moved
+//     - Useful when the binary was built in one location but the project moved
+//  2. Embedded project root (set at build time via -ldflags)
+//     - Automatically embedded during compilation by start_console.ps1 (Windows)
+//     - or Makefile (macOS)
+//  3. If neither is valid, the process exits with detailed error messages
 //
-// The function validates that the detected project root contains all required
+// It validates that the detected project root contains all required
 // subdirectories (scripts/, terraform/, k8s/) before proceeding.
-//
-// Returns a fully initialized model ready for the TUI, or exits the program
-// if project root cannot be determined.
-func initialModel() model {
+func resolveProjectRoot() string {
 	var projectRoot string
 	var validationErrors []string
 
@@ -210,7 +620,7 @@ func initialModel() model {
 	// Provide platform-specific instructions to help users resolve the issue
 	if projectRoot == "" {
 		fmt.Fprintf(os.Stderr, "❌ Error: Project root not found or invalid\n\n")
-		
+
 		// Report embedded path status and issues
 		if embeddedProjectRoot != "" {
 			fmt.Fprintf(os.Stderr, "Embedded project root: %s\n", embeddedProjectRoot)
@@ -254,76 +664,128 @@ func initialModel() model {
 		os.Exit(1)
 	}
 
+	return projectRoot
+}
+
+// initialModel initializes the TUI application model using the resolved
+// project root and command definitions. This function is called once at
+// application startup.
+//
+// Returns a fully initialized model ready for the TUI, or exits the program
+// if project root cannot be determined.
+func initialModel() model {
+	projectRoot := resolveProjectRoot()
+
 	// Build the path to the scripts directory for command definitions
 	scriptsPath := filepath.Join(projectRoot, "scripts")
 
-	// Initialize and return the model with all available commands
-	// Each command represents a script that can be executed from the TUI menu
+	cats := loadCategories(projectRoot, scriptsPath)
+
+	historyPath, err := history.DefaultPath()
+	if err != nil {
+		// History is a convenience, not a requirement: fall back to an
+		// empty, unpersisted history rather than failing startup.
+		historyPath = ""
+	}
+	var hist []history.Entry
+	if historyPath != "" {
+		hist, _ = history.Load(historyPath)
+	}
+	if recentCat := buildRecentCategory(cats, hist); recentCat != nil {
+		cats = append([]category{*recentCat}, cats...)
+	}
+
+	auditPath, err := history.AuditLogPath()
+	if err != nil {
+		// Same reasoning as historyPath above: the audit log is important
+		// for compliance but must never block the TUI from starting.
+		auditPath = ""
+	}
+	var auditLog []history.AuditEntry
+	if auditPath != "" {
+		auditLog, _ = history.LoadAudit(auditPath)
+	}
+
+	flat := buildFlatIndex(cats)
+	cmdTotal := 0
+	startCursor := 0
+	for i, e := range flat {
+		if !e.isCategory {
+			if cmdTotal == 0 {
+				startCursor = i
+			}
+			cmdTotal++
+		}
+	}
+
 	return model{
 		projectRoot: projectRoot,
-		commands: []command{
-			{
-				title:       "Validate Prerequisites",
-				description: "Check required tools, AWS credentials, and deployment readiness",
-				script:      filepath.Join(scriptsPath, "validate-deployment.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Quick Deploy",
-				description: "Deploy infrastructure, OpenEMR, and monitoring stack in one command",
-				script:      filepath.Join(scriptsPath, "quick-deploy.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Check Deployment Health",
-				description: "Validate current deployment status and infrastructure health",
-				script:      filepath.Join(scriptsPath, "validate-deployment.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Backup Deployment",
-				description: "Create comprehensive backup of RDS, Kubernetes configs, and application data",
-				script:      filepath.Join(scriptsPath, "backup.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Clean Deployment",
-				description: "Remove application layer while preserving infrastructure",
-				script:      filepath.Join(scriptsPath, "clean-deployment.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Destroy Infrastructure",
-				description: "Completely destroy all infrastructure resources (use with caution)",
-				script:      filepath.Join(scriptsPath, "destroy.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Check Component Versions",
-				description: "Check for available updates across all project components",
-				script:      filepath.Join(scriptsPath, "version-manager.sh"),
-				args:        []string{"check"},
-			},
-			{
-				title:       "Check OpenEMR Versions",
-				description: "Discover available OpenEMR Docker image versions from Docker Hub",
-				script:      filepath.Join(scriptsPath, "check-openemr-versions.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Search Codebase",
-				description: "Search for terms across the entire codebase (interactive)",
-				script:      filepath.Join(scriptsPath, "search-codebase.sh"),
-				args:        []string{},
-			},
-			{
-				title:       "Deploy Training Setup",
-				description: "Deploy OpenEMR with synthetic patient data for training/testing",
-				script:      filepath.Join(scriptsPath, "deploy-training-openemr-setup.sh"),
-				args:        []string{"--use-default-dataset", "--max-records", "100"},
-			},
-		},
+		categories:  cats,
+		flatIndex:   flat,
+		cursor:      startCursor,
+		cmdCount:    cmdTotal,
+		historyPath: historyPath,
+		history:     hist,
+		auditPath:   auditPath,
+		auditLog:    auditLog,
+	}
+}
+
+// moveCursor shifts the cursor by delta positions within flatIndex, skipping
+// over category headers and wrapping around at either end so the menu never
+// lands on anything but a runnable command.
+func (m *model) moveCursor(delta int) {
+	if len(m.flatIndex) == 0 {
+		return
+	}
+	n := len(m.flatIndex)
+	pos := m.cursor
+	for i := 0; i < n; i++ {
+		pos = (pos + delta + n) % n
+		if !m.flatIndex[pos].isCategory {
+			m.cursor = pos
+			return
+		}
+	}
+}
+
+// jumpTo moves the cursor directly to the first (first=true) or last command
+// in the flattened index.
+func (m *model) jumpTo(first bool) {
+	if first {
+		for i, e := range m.flatIndex {
+			if !e.isCategory {
+				m.cursor = i
+				return
+			}
+		}
+	} else {
+		for i := len(m.flatIndex) - 1; i >= 0; i-- {
+			if !m.flatIndex[i].isCategory {
+				m.cursor = i
+				return
+			}
+		}
+	}
+}
+
+// commandPosition returns the 1-based ordinal of the command under the
+// cursor among all commands (ignoring category headers), for display as
+// "3/12" in the menu footer.
+func (m *model) commandPosition() int {
+	pos := 0
+	for i := 0; i <= m.cursor && i < len(m.flatIndex); i++ {
+		if !m.flatIndex[i].isCategory {
+			pos++
+		}
 	}
+	return pos
+}
+
+// commandAt resolves a flatIndex position to its underlying command.
+func (m *model) commandAt(idx int) command {
+	e := m.flatIndex[idx]
+	return m.categories[e.catIdx].commands[e.cmdIdx]
 }
 
 // Init is called by Bubbletea when the program starts.
@@ -336,528 +798,778 @@ func (m model) Init() tea.Cmd {
 // This is the core of the Bubbletea Model-Update-View pattern.
 //
 // Message handling order:
-//   1. Command execution results (outputMsg, errorMsg) - handled first
-//   2. User input during command execution (only quit keys allowed)
-//   3. User input in menu mode (navigation and selection)
+//  1. User input and streamed output while the execution pane is open
+//  2. User input while a prompt form is open
+//  3. User input while a destructive-command confirmation is pending
+//  4. User input while fuzzy search is active
+//  5. User input while the history browser is open
+//  6. User input in normal menu mode (navigation and selection)
 //
 // Returns the updated model and any commands to run (for async operations).
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Handle command execution results first (these come from async operations)
-	switch msg := msg.(type) {
-	case outputMsg:
-		m.output = string(msg)
-		m.executing = false
-		// Force a refresh by returning a command that does nothing
-		// This ensures the View() function is called to display the result
-		return m, tea.Batch()
-	case errorMsg:
-		m.error = string(msg)
-		m.executing = false
-		// Force a refresh to display the error message
-		return m, tea.Batch()
-	}
-
-	// If a command is currently executing, only allow quit operations
-	// This prevents users from navigating away while a command is running
 	if m.executing {
-		switch msg := msg.(type) {
-		case tea.KeyMsg:
-			// Allow user to cancel/return from execution view
-			if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc || msg.Type == tea.KeyEnter {
-				m.executing = false
-				m.output = ""
-				m.error = ""
-				return m, nil
-			}
+		return m.updateExecuting(msg)
+	}
+
+	if m.input != nil {
+		return m.updateInput(msg)
+	}
+
+	if m.challenge != nil {
+		return m.updateChallenge(msg)
+	}
+
+	if m.search != nil {
+		return m.updateSearch(msg)
+	}
+
+	if m.historyBrowser != nil {
+		return m.updateHistoryBrowser(msg)
+	}
+
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.showHelp {
+		if key.Code == tea.KeyEscape || key.Code == '?' {
+			m.showHelp = false
 		}
 		return m, nil
 	}
 
-	// Handle user input in menu mode
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
-			// Quit the application
+	switch key.Code {
+	case tea.KeyEscape:
+		m.quitting = true
+		return m, tea.Quit
+	case 'c':
+		if key.Mod == tea.ModCtrl {
 			m.quitting = true
 			return m, tea.Quit
+		}
+	case 'q':
+		m.quitting = true
+		return m, tea.Quit
+	case '?':
+		m.showHelp = !m.showHelp
+	case '/':
+		m.search = newSearchState(m.categories)
+	case 'h':
+		m.historyBrowser = newHistoryBrowserState(m.auditLog)
+	case tea.KeyUp, 'k':
+		m.moveCursor(-1)
+	case tea.KeyDown, 'j':
+		m.moveCursor(1)
+	case 'g':
+		m.jumpTo(true)
+	case 'G':
+		m.jumpTo(false)
+	case 'r':
+		return m.rerunLast()
+	case tea.KeyEnter:
+		return m.activate(m.cursor)
+	}
 
-		case tea.KeyUp:
-			// Navigate up in the menu (with wrap-around)
-			if m.cursor > 0 {
-				m.cursor--
-			} else {
-				m.cursor = len(m.commands) - 1
-			}
+	return m, nil
+}
+
+// rerunLast re-executes the most recent history entry with its original
+// arguments, bypassing prompts and confirmation entirely (the user already
+// supplied and confirmed these values once).
+func (m model) rerunLast() (tea.Model, tea.Cmd) {
+	if len(m.history) == 0 {
+		return m, nil
+	}
+	last := m.history[len(m.history)-1]
+	ci, cj, ok := m.findCommand(last.Category, last.Title)
+	if !ok {
+		return m, nil
+	}
+	cmd := m.categories[ci].commands[cj]
+	for i, e := range m.flatIndex {
+		if !e.isCategory && e.catIdx == ci && e.cmdIdx == cj {
+			m.cursor = i
+			break
+		}
+	}
+	return m.startExecution(cmd, last.Args, last.Values)
+}
 
-		case tea.KeyDown:
-			// Navigate down in the menu (with wrap-around)
-			if m.cursor < len(m.commands)-1 {
-				m.cursor++
+// startExecution marks cmd as running, recording its arguments, submitted
+// prompt values, and start time so the completion handler can persist a
+// history entry, then opens the streaming log pane and kicks off the
+// script in the background. values is nil for commands with no prompts.
+//
+// Commands tagged modeNewWindow skip all of that: they never enter the
+// executing view at all, since their output isn't coming back to us to
+// stream. Launch them detached via runInExternalTerminal and stay on the
+// menu with a one-line status instead.
+func (m model) startExecution(cmd command, args, values []string) (tea.Model, tea.Cmd) {
+	if cmd.execMode == modeNewWindow {
+		m.selected = m.cursor
+		if err := runInExternalTerminal(cmd, args); err != nil {
+			m.launchMessage = fmt.Sprintf("failed to launch %q in a new terminal: %s", cmd.title, err)
+		} else {
+			m.launchMessage = fmt.Sprintf("launched %q in a new terminal window", cmd.title)
+		}
+		return m, nil
+	}
+
+	m.selected = m.cursor
+	m.executing = true
+	m.execDone = false
+	m.execCancelled = false
+	m.logLines = nil
+	m.logScroll = 0
+	m.spinnerFrame = 0
+	m.transcriptMsg = ""
+	m.launchMessage = ""
+	m.execArgs = args
+	m.execValues = values
+	m.execStart = time.Now()
+
+	ch := make(chan tea.Msg, 256)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.execCh = ch
+	m.execCancel = cancel
+
+	return m, tea.Batch(runScript(ctx, cmd, args, ch), waitForLog(ch), tickSpinner())
+}
+
+// logViewportLines is how many log lines are visible at once in the
+// executing view.
+const logViewportLines = 16
+
+// appendLogLine adds l to the ring buffer, dropping the oldest line once
+// maxLogLines is exceeded.
+func (m *model) appendLogLine(l logLine) {
+	m.logLines = append(m.logLines, l)
+	if len(m.logLines) > maxLogLines {
+		m.logLines = m.logLines[len(m.logLines)-maxLogLines:]
+	}
+}
+
+// scrollLog moves the log viewport by delta lines, clamped so it never
+// scrolls past the top or below the live tail.
+func (m *model) scrollLog(delta int) {
+	max := len(m.logLines) - logViewportLines
+	if max < 0 {
+		max = 0
+	}
+	m.logScroll += delta
+	if m.logScroll < 0 {
+		m.logScroll = 0
+	}
+	if m.logScroll > max {
+		m.logScroll = max
+	}
+}
+
+// updateExecuting handles messages while the streaming log pane is open:
+// incoming output lines, the run's completion, scroll keys, cancellation,
+// and dismissal.
+func (m model) updateExecuting(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case logLineMsg:
+		m.appendLogLine(logLine(msg))
+		return m, waitForLog(m.execCh)
+	case finishMsg:
+		m.execDone = true
+		m.execExitCode = msg.exitCode
+		m.execCancelled = msg.cancelled
+		m.execCancel = nil
+		m.recordHistory(msg.exitCode)
+		return m, nil
+	case tickMsg:
+		if m.execDone {
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, tickSpinner()
+	case tea.KeyPressMsg:
+		switch msg.Code {
+		case 'c':
+			if msg.Mod == tea.ModCtrl {
+				if !m.execDone {
+					if m.execCancel != nil {
+						m.execCancel()
+					}
+					return m, nil
+				}
+				m.executing = false
+				return m, nil
+			}
+		case tea.KeyEnter, tea.KeyEscape:
+			if m.execDone {
+				m.executing = false
+			}
+		case 's':
+			path, err := saveTranscript(m.commandAt(m.selected).title, m.logLines, time.Now())
+			if err != nil {
+				m.transcriptMsg = fmt.Sprintf("failed to save transcript: %s", err)
 			} else {
-				m.cursor = 0
+				m.transcriptMsg = "saved transcript to " + path
 			}
+			return m, nil
+		case tea.KeyPgUp:
+			m.scrollLog(logViewportLines)
+		case tea.KeyPgDown:
+			m.scrollLog(-logViewportLines)
+		case tea.KeyHome:
+			m.scrollLog(len(m.logLines))
+		case tea.KeyEnd:
+			m.scrollLog(-len(m.logLines))
+		}
+	}
+	return m, nil
+}
 
-		case tea.KeyEnter:
-			// Execute the selected command
-			m.selected = m.cursor
-			m.executing = true
-			m.output = ""
-			m.error = ""
-			// executeCommand returns a tea.Cmd that will send a message when done
-			return m, m.executeCommand(m.commands[m.cursor])
+// tickMsg drives periodic re-renders for things that change without user
+// input: the execution spinner and the challenge countdown.
+type tickMsg time.Time
+
+// tickSpinner schedules the next animation frame.
+func tickSpinner() tea.Cmd {
+	return tea.Tick(150*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// recordHistory appends an entry for the just-finished execution to both
+// the in-memory history and the persisted history file. Persistence
+// failures are swallowed: history is a convenience feature and must never
+// block the TUI or surface as a user-facing error.
+func (m *model) recordHistory(exitCode int) {
+	e := m.flatIndex[m.selected]
+	cmd := m.categories[e.catIdx].commands[e.cmdIdx]
+	categoryName := categoryNameFor(m.categories, e.catIdx)
+
+	if m.historyPath != "" {
+		entry := history.Entry{
+			Category:   categoryName,
+			Title:      cmd.title,
+			Args:       m.execArgs,
+			Values:     redactSensitive(cmd.prompts, m.execValues),
+			Timestamp:  m.execStart,
+			ExitCode:   exitCode,
+			DurationMs: time.Since(m.execStart).Milliseconds(),
 		}
+		m.history = append(m.history, entry)
+		_ = history.Append(m.historyPath, entry)
 	}
 
-	return m, nil
+	m.recordAudit(categoryName, cmd, exitCode)
 }
 
-// executeCommand launches a bash script in a new terminal window.
-// The implementation differs significantly between platforms:
-//
-// macOS:
-//   - Uses osascript to open a new Terminal.app window
-//   - Executes the bash script directly in the terminal
-//
-// Windows:
-//   - Uses PowerShell Start-Process to open a new PowerShell window
-//   - Generates a temporary PowerShell script that:
-//     1. Detects available bash (Git Bash, WSL, or system bash)
-//     2. Converts Windows paths to appropriate format for the detected bash
-//     3. Executes the bash script with proper error handling
-//     4. Keeps the window open even on errors for debugging
-//
-// The function returns a tea.Cmd that will send an outputMsg or errorMsg
-// when the command execution is initiated (not when it completes, since
-// execution happens in a separate terminal window).
-func (m model) executeCommand(cmd command) tea.Cmd {
-	return func() tea.Msg {
-		// Validate that the script file exists before attempting execution
-		if _, err := os.Stat(cmd.script); os.IsNotExist(err) {
-			msg := fmt.Sprintf("Script not found: %s\n\n", cmd.script)
-			if embeddedProjectRoot != "" {
-				msg += fmt.Sprintf("Embedded project root: %s\n", embeddedProjectRoot)
+// recordAudit appends an AuditEntry for the just-finished execution to both
+// the in-memory audit log and the persisted JSONL audit file, auto-saving
+// the run's full captured output as a transcript so two runs of the same
+// command can later be diffed from the history browser. Like recordHistory,
+// failures here are swallowed rather than surfaced: an audit trail gap must
+// never block the TUI.
+func (m *model) recordAudit(categoryName string, cmd command, exitCode int) {
+	if m.auditPath == "" {
+		return
+	}
+
+	transcriptPath, err := saveTranscript(cmd.title, m.logLines, m.execStart)
+	if err != nil {
+		transcriptPath = ""
+	}
+
+	backendName := ""
+	if backend, err := detectShellBackend(exec.LookPath); err == nil {
+		backendName = backend.Name()
+	}
+
+	entry := history.AuditEntry{
+		Category:       categoryName,
+		Title:          cmd.title,
+		ScriptPath:     cmd.script,
+		Args:           m.execArgs,
+		StartTime:      m.execStart,
+		EndTime:        time.Now(),
+		ExitCode:       exitCode,
+		Platform:       runtime.GOOS,
+		ProjectRoot:    m.projectRoot,
+		ShellBackend:   backendName,
+		TranscriptPath: transcriptPath,
+	}
+	m.auditLog = append(m.auditLog, entry)
+	_ = history.AppendAudit(m.auditPath, entry)
+}
+
+// redactSensitive returns a copy of values with any field marked sensitive
+// blanked out, so typed-in secrets (API keys, passwords, ...) are never
+// written to the persisted history file or offered back as a history-cycle
+// suggestion. The actual argv used to run the command (m.execArgs) is
+// unaffected, since it must remain correct for "re-run last" to work.
+func redactSensitive(fields []inputField, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := append([]string{}, values...)
+	for i, f := range fields {
+		if f.sensitive && i < len(out) {
+			out[i] = ""
+		}
+	}
+	return out
+}
+
+// categoryNameFor resolves the "real" category name for an entry, mapping
+// the synthetic Recent bucket back to the command's original category so
+// history entries are never recorded against "Recent" itself.
+func categoryNameFor(cats []category, catIdx int) string {
+	cat := cats[catIdx]
+	if cat.name != recentCategoryName {
+		return cat.name
+	}
+	// The Recent bucket only ever contains copies of commands that live
+	// elsewhere; find that original category.
+	for _, c := range cats {
+		if c.name == recentCategoryName {
+			continue
+		}
+		for _, cmd := range c.commands {
+			for _, rc := range cat.commands {
+				if cmd.title == rc.title {
+					return c.name
+				}
 			}
-			msg += "Possible solutions:\n"
-			msg += "1. If you moved the project, set OPENEMR_EKS_PROJECT_ROOT environment variable:\n"
-			msg += "   export OPENEMR_EKS_PROJECT_ROOT=/path/to/openemr-on-eks\n"
-			msg += "2. Run the console from the project root directory\n"
-			msg += "3. Reinstall the console from the correct project location"
-			return errorMsg(msg)
 		}
+	}
+	return cat.name
+}
 
-		// Ensure the script has execute permissions (important for Unix-like systems)
-		// On Windows, this is a no-op but doesn't hurt
-		os.Chmod(cmd.script, 0755)
+// activate executes or opens a prompt for the command at the given flatIndex
+// position. Selecting a category header simply advances to its first
+// command instead of doing nothing.
+func (m model) activate(idx int) (tea.Model, tea.Cmd) {
+	e := m.flatIndex[idx]
+	if e.isCategory {
+		m.cursor = idx
+		m.moveCursor(1)
+		return m, nil
+	}
 
-		// Prepare script path, arguments, and working directory
-		scriptPath := cmd.script
-		scriptArgs := strings.Join(cmd.args, " ")
-		workingDir := filepath.Dir(cmd.script)
-
-		// Platform-specific execution: open command in a new terminal window
-		if runtime.GOOS == "darwin" {
-			// macOS: Use osascript to open a new Terminal.app window
-			// osascript allows us to programmatically control Terminal.app
-			// and execute commands in new windows.
-			//
-			// We escape single quotes by replacing them with: '"\''"'
-			// This is the standard shell escaping technique for single quotes
-			escapedScriptPath := strings.ReplaceAll(scriptPath, "'", "'\"'\"'")
-			escapedArgs := strings.ReplaceAll(scriptArgs, "'", "'\"'\"'")
-			escapedWorkingDir := strings.ReplaceAll(workingDir, "'", "'\"'\"'")
-
-			// Build the command string that will be executed in the new terminal
-			// The command changes directory, runs the script, then waits for user input
-			command := fmt.Sprintf("cd '%s' && '%s' %s; echo ''; echo 'Press any key and then return to go back to the command line'; read -n 1", escapedWorkingDir, escapedScriptPath, escapedArgs)
-			
-			// Use osascript to tell Terminal.app to execute the command in a new window
-			execCmd := exec.Command("osascript", "-e", fmt.Sprintf(`tell application "Terminal" to do script "%s"`, command))
-
-			// Execute the command to open terminal
-			if err := execCmd.Run(); err != nil {
-				return errorMsg(fmt.Sprintf("Failed to open terminal window: %s", err.Error()))
-			}
-		} else if runtime.GOOS == "windows" {
-			// Windows: Use PowerShell Start-Process to open a new PowerShell window
-			// 
-			// Windows execution is complex because:
-			// 1. We need to detect which bash is available (Git Bash, WSL, or system bash)
-			// 2. Each bash variant requires different path formats:
-			//    - Git Bash: /c/Users/... (Unix-style with drive letter conversion)
-			//    - WSL: /mnt/c/Users/... (uses wslpath for conversion)
-			//    - System bash: Depends on installation, usually Unix-style
-			// 3. PowerShell commands (like Set-Location) need Windows paths
-			// 4. We generate a temporary PowerShell script to avoid complex escaping issues
-			//
-			// Path conversion strategy:
-			// - Convert to Unix-style for Git Bash and system bash
-			// - Keep Windows-style for WSL (WSL will convert via wslpath)
-			// - Keep original Windows path for PowerShell Set-Location cmdlet
-			
-			// Convert Windows paths to Unix-style paths for Git Bash
-			scriptPathUnix := convertWindowsPathToUnix(scriptPath)
-			workingDirUnix := convertWindowsPathToUnix(workingDir)
-			
-			// Keep Windows paths with forward slashes for WSL (WSL prefers / over \)
-			scriptPathWin := strings.ReplaceAll(scriptPath, "\\", "/")
-			workingDirWin := strings.ReplaceAll(workingDir, "\\", "/")
-			
-			// Keep the original Windows path with backslashes for PowerShell Set-Location
-			// PowerShell's Set-Location cmdlet expects Windows paths, not Unix-style paths
-			workingDirWinPS := workingDir
-			
-			// Escape single quotes for PowerShell (PowerShell uses '' to escape single quotes)
-			// This is different from bash which uses '\'' for escaping
-			escapedScriptPathUnix := strings.ReplaceAll(scriptPathUnix, "'", "''")
-			escapedScriptPathWin := strings.ReplaceAll(scriptPathWin, "'", "''")
-			escapedArgs := strings.ReplaceAll(scriptArgs, "'", "''")
-			escapedWorkingDirUnix := strings.ReplaceAll(workingDirUnix, "'", "''")
-			escapedWorkingDirWin := strings.ReplaceAll(workingDirWin, "'", "''")
-			escapedWorkingDirWinPS := strings.ReplaceAll(workingDirWinPS, "'", "''")
-
-			// Build PowerShell script that will be written to a temporary file
-			// We use bytes.Buffer instead of string concatenation for:
-			// 1. Better performance with many string operations
-			// 2. Explicit control over newlines (\r\n for Windows)
-			// 3. Cleaner code structure
-			//
-			// The script structure:
-			// 1. Set error handling and window title
-			// 2. Display header information
-			// 3. Try block: Detect bash and execute script
-			// 4. Catch block: Display detailed error information
-			// 5. Finally block: Keep window open for user to read output
-			var scriptBuf bytes.Buffer
-			
-			// Set error handling: Continue on errors so we can catch and display them
-			scriptBuf.WriteString("$ErrorActionPreference = 'Continue'\r\n")
-			
-			// Set window title for easy identification
-			scriptBuf.WriteString("$Host.UI.RawUI.WindowTitle = 'OpenEMR EKS Console - Script Execution'\r\n")
-			
-			// Display header with colored output
-			scriptBuf.WriteString("Write-Host 'OpenEMR EKS Console - Script Execution' -ForegroundColor Cyan\r\n")
-			scriptBuf.WriteString("Write-Host '========================================' -ForegroundColor Cyan\r\n")
-			scriptBuf.WriteString("Write-Host ''\r\n")
-			
-			// Begin try-catch-finally block for error handling
-			scriptBuf.WriteString("try {\r\n")
-			// Set up path variables that will be used by the bash detection logic
-			scriptBuf.WriteString(fmt.Sprintf("  $workingDirUnix = '%s'\r\n", escapedWorkingDirUnix))
-			scriptBuf.WriteString(fmt.Sprintf("  $scriptPathUnix = '%s'\r\n", escapedScriptPathUnix))
-			scriptBuf.WriteString(fmt.Sprintf("  $scriptArgs = '%s'\r\n", escapedArgs))
-			
-			// Initialize variables that will be set during bash detection
-			scriptBuf.WriteString("  $bashCmd = $null\r\n")
-			scriptBuf.WriteString("  $finalScriptPath = $null\r\n")
-			scriptBuf.WriteString("  $finalWorkingDir = $null\r\n")
-			scriptBuf.WriteString("  $finalWorkingDirPS = $null\r\n")
-			
-			scriptBuf.WriteString("  Write-Host 'Looking for bash...' -ForegroundColor Cyan\r\n")
-			
-			// Bash detection strategy (in priority order):
-			// 1. Git Bash - Most common on Windows, uses /c/ path format
-			// 2. WSL - Windows Subsystem for Linux, uses /mnt/c/ path format
-			// 3. System bash - Any bash in PATH (less common)
-			//
-			// We try Git Bash first because it's the most common installation
-			scriptBuf.WriteString("  # Try Git Bash first\r\n")
-			// Common Git Bash installation paths (check all to handle different install locations)
-			scriptBuf.WriteString("  $gitBashPaths = @('C:\\Program Files\\Git\\bin\\bash.exe', 'C:\\Program Files (x86)\\Git\\bin\\bash.exe', \"$env:LOCALAPPDATA\\Programs\\Git\\bin\\bash.exe\")\r\n")
-			// Check each Git Bash path until we find one that exists
-			scriptBuf.WriteString("  Write-Host 'Checking Git Bash locations...' -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("  foreach ($path in $gitBashPaths) {\r\n")
-			scriptBuf.WriteString("    Write-Host \"  Checking: $path\" -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("    if (Test-Path $path) {\r\n")
-			// Git Bash found: use Unix-style paths (already converted)
-			scriptBuf.WriteString("      $bashCmd = $path\r\n")
-			scriptBuf.WriteString(fmt.Sprintf("      $finalScriptPath = '%s'\r\n", escapedScriptPathUnix))
-			scriptBuf.WriteString(fmt.Sprintf("      $finalWorkingDir = '%s'\r\n", escapedWorkingDirUnix))
-			scriptBuf.WriteString(fmt.Sprintf("      $finalWorkingDirPS = '%s'\r\n", escapedWorkingDirWinPS))
-			scriptBuf.WriteString("      Write-Host \"Found Git Bash at: $path\" -ForegroundColor Green\r\n")
-			scriptBuf.WriteString("      break\r\n")
-			scriptBuf.WriteString("    }\r\n")
-			scriptBuf.WriteString("  }\r\n")
-			
-			// If Git Bash not found, try WSL (Windows Subsystem for Linux)
-			// WSL requires different path handling: we use wslpath to convert Windows paths
-			scriptBuf.WriteString("  # Try WSL bash\r\n")
-			scriptBuf.WriteString("  if (-not $bashCmd) {\r\n")
-			scriptBuf.WriteString("    Write-Host 'Checking for WSL...' -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("    $wslCmd = Get-Command wsl -ErrorAction SilentlyContinue\r\n")
-			scriptBuf.WriteString("    if ($wslCmd) {\r\n")
-			scriptBuf.WriteString("      Write-Host 'WSL found, converting paths...' -ForegroundColor Cyan\r\n")
-			// WSL path conversion: use Windows paths (with forward slashes) and let wslpath convert them
-			scriptBuf.WriteString(fmt.Sprintf("      $scriptPathWin = '%s'\r\n", escapedScriptPathWin))
-			scriptBuf.WriteString(fmt.Sprintf("      $workingDirWin = '%s'\r\n", escapedWorkingDirWin))
-			// Use wslpath -a to convert Windows absolute path to WSL path format
-			// This handles the /mnt/c/ conversion automatically
-			scriptBuf.WriteString("      $wslScriptPath = (wsl wslpath -a $scriptPathWin 2>$null).Trim()\r\n")
-			scriptBuf.WriteString("      $wslWorkingDir = (wsl wslpath -a $workingDirWin 2>$null).Trim()\r\n")
-			scriptBuf.WriteString("      if ($wslScriptPath -and $wslWorkingDir) {\r\n")
-			// WSL found and paths converted successfully
-			scriptBuf.WriteString("        $bashCmd = 'wsl'\r\n")
-			scriptBuf.WriteString("        $finalScriptPath = $wslScriptPath\r\n")
-			scriptBuf.WriteString("        $finalWorkingDir = $wslWorkingDir\r\n")
-			scriptBuf.WriteString(fmt.Sprintf("        $finalWorkingDirPS = '%s'\r\n", escapedWorkingDirWinPS))
-			scriptBuf.WriteString("        Write-Host \"Using WSL with path: $finalScriptPath\" -ForegroundColor Green\r\n")
-			scriptBuf.WriteString("      } else {\r\n")
-			scriptBuf.WriteString("        Write-Host 'WSL path conversion failed' -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("      }\r\n")
-			scriptBuf.WriteString("    } else {\r\n")
-			scriptBuf.WriteString("      Write-Host 'WSL not found' -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("    }\r\n")
-			scriptBuf.WriteString("  }\r\n")
-			
-			// Last resort: check for any bash in the system PATH
-			// This is less common but some users may have bash installed elsewhere
-			scriptBuf.WriteString("  # Try system bash\r\n")
-			scriptBuf.WriteString("  if (-not $bashCmd) {\r\n")
-			scriptBuf.WriteString("    Write-Host 'Checking for system bash in PATH...' -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("    $sysBash = Get-Command bash -ErrorAction SilentlyContinue\r\n")
-			scriptBuf.WriteString("    if ($sysBash) {\r\n")
-			// System bash found: assume it uses Unix-style paths (like Git Bash)
-			scriptBuf.WriteString("      $bashCmd = 'bash'\r\n")
-			scriptBuf.WriteString(fmt.Sprintf("      $finalScriptPath = '%s'\r\n", escapedScriptPathUnix))
-			scriptBuf.WriteString(fmt.Sprintf("      $finalWorkingDir = '%s'\r\n", escapedWorkingDirUnix))
-			scriptBuf.WriteString(fmt.Sprintf("      $finalWorkingDirPS = '%s'\r\n", escapedWorkingDirWinPS))
-			scriptBuf.WriteString("      Write-Host \"Found system bash at: $($sysBash.Source)\" -ForegroundColor Green\r\n")
-			scriptBuf.WriteString("    } else {\r\n")
-			scriptBuf.WriteString("      Write-Host 'System bash not found in PATH' -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("    }\r\n")
-			scriptBuf.WriteString("  }\r\n")
-			// Execute the script if bash was found
-			scriptBuf.WriteString("  if ($bashCmd) {\r\n")
-			scriptBuf.WriteString("    try {\r\n")
-			// Set-Location requires Windows paths (with backslashes), not Unix-style paths
-			// This is why we maintain $finalWorkingDirPS separately
-			scriptBuf.WriteString("      # Use Windows path for PowerShell Set-Location\r\n")
-			scriptBuf.WriteString("      Set-Location $finalWorkingDirPS\r\n")
-			scriptBuf.WriteString("      Write-Host \"Working directory: $finalWorkingDir\" -ForegroundColor Cyan\r\n")
-			scriptBuf.WriteString("      Write-Host \"Executing: $finalScriptPath $scriptArgs\" -ForegroundColor Cyan\r\n")
-			scriptBuf.WriteString("      Write-Host ''\r\n")
-			
-			// WSL requires special handling: we need to pass the entire command as a string
-			// to bash -c, with proper escaping of quotes within the command
-			scriptBuf.WriteString("      if ($bashCmd -eq 'wsl') {\r\n")
-			scriptBuf.WriteString("        # For WSL, properly escape the command\r\n")
-			scriptBuf.WriteString("        # Use backticks (`) to escape quotes within the double-quoted string\r\n")
-			scriptBuf.WriteString("        $escapedCmd = \"cd `\"$finalWorkingDir`\" && bash `\"$finalScriptPath`\" $scriptArgs\"\r\n")
-			scriptBuf.WriteString("        wsl bash -c $escapedCmd\r\n")
-			scriptBuf.WriteString("      } else {\r\n")
-			// Git Bash and system bash can accept the script path and arguments separately
-			// This is simpler and avoids complex escaping issues
-			scriptBuf.WriteString("        # For Git Bash or system bash, pass script path and args separately\r\n")
-			scriptBuf.WriteString("        if ($scriptArgs) {\r\n")
-			scriptBuf.WriteString("          $argArray = $scriptArgs -split ' '\r\n")
-			scriptBuf.WriteString("          & $bashCmd $finalScriptPath $argArray\r\n")
-			scriptBuf.WriteString("        } else {\r\n")
-			scriptBuf.WriteString("          & $bashCmd $finalScriptPath\r\n")
-			scriptBuf.WriteString("        }\r\n")
-			scriptBuf.WriteString("      }\r\n")
-			
-			// Check exit code and display warning if script failed
-			// Note: We don't treat non-zero exit codes as errors here because
-			// the script itself may have valid reasons to exit with non-zero (e.g., validation failures)
-			scriptBuf.WriteString("      if ($LASTEXITCODE -ne 0) {\r\n")
-			scriptBuf.WriteString("        Write-Host ''\r\n")
-			scriptBuf.WriteString("        Write-Host \"Script exited with code $LASTEXITCODE\" -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("      }\r\n")
-			scriptBuf.WriteString("    } catch {\r\n")
-			// Catch block: Display detailed error information for debugging
-			// This helps users understand what went wrong
-			scriptBuf.WriteString("      Write-Host ''\r\n")
-			scriptBuf.WriteString("      Write-Host \"Error executing script: $_\" -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("      Write-Host \"Bash command: $bashCmd\" -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("      Write-Host \"Script path: $finalScriptPath\" -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("      Write-Host \"Working dir: $finalWorkingDir\" -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("      Write-Host \"Script args: $scriptArgs\" -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("    }\r\n")
-			scriptBuf.WriteString("  } else {\r\n")
-			// No bash found: provide helpful installation instructions
-			scriptBuf.WriteString("    Write-Host 'Error: bash not found.' -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("    Write-Host ''\r\n")
-			scriptBuf.WriteString("    Write-Host 'Please install one of the following:' -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("    Write-Host '  1. Git Bash: https://git-scm.com/download/win' -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("    Write-Host '  2. WSL (Windows Subsystem for Linux)' -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("  }\r\n")
-			// Outer catch block: Handle any unexpected errors in the PowerShell script itself
-			scriptBuf.WriteString("} catch {\r\n")
-			scriptBuf.WriteString("  Write-Host ''\r\n")
-			scriptBuf.WriteString("  Write-Host 'Unexpected error occurred:' -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("  Write-Host $_.Exception.Message -ForegroundColor Red\r\n")
-			scriptBuf.WriteString("  Write-Host $_.ScriptStackTrace -ForegroundColor Gray\r\n")
-			scriptBuf.WriteString("} finally {\r\n")
-			// Finally block: Always keep the window open so users can read output/errors
-			// This is critical for debugging - we want to see what happened even if there's an error
-			scriptBuf.WriteString("  Write-Host ''\r\n")
-			scriptBuf.WriteString("  Write-Host 'Press any key to close this window...' -ForegroundColor Yellow\r\n")
-			scriptBuf.WriteString("  try {\r\n")
-			// ReadKey waits for user input before closing
-			scriptBuf.WriteString("    $null = $Host.UI.RawUI.ReadKey('NoEcho,IncludeKeyDown')\r\n")
-			scriptBuf.WriteString("  } catch {\r\n")
-			// Fallback: If ReadKey fails (e.g., in some terminal environments), wait 5 seconds
-			// This gives users time to read the output before the window closes
-			scriptBuf.WriteString("    # If ReadKey fails, wait a bit then exit\r\n")
-			scriptBuf.WriteString("    Start-Sleep -Seconds 5\r\n")
-			scriptBuf.WriteString("  }\r\n")
-			scriptBuf.WriteString("}\r\n")
-			powershellScript := scriptBuf.String()
-
-			// Create a temporary PowerShell script file to avoid complex escaping issues
-			// Why use a temp file instead of inline execution?
-			// 1. Avoids PowerShell's complex quote escaping rules
-			// 2. More reliable than base64 encoding (which has encoding issues)
-			// 3. Easier to debug (users can inspect the generated script)
-			// 4. Handles multi-line scripts cleanly
-			tmpScript, err := ioutil.TempFile("", "openemr-console-*.ps1")
-			if err != nil {
-				return errorMsg(fmt.Sprintf("Failed to create temporary script: %s", err.Error()))
-			}
-			// Note: We intentionally don't delete the temp file immediately
-			// The file will be cleaned up by Windows temp file cleanup (typically on reboot)
-			// This is acceptable because:
-			// 1. Temp files are small (a few KB)
-			// 2. Windows handles cleanup automatically
-			// 3. Immediate deletion could cause issues if PowerShell is still reading it
-			
-			// Write UTF-8 BOM (Byte Order Mark) for PowerShell compatibility
-			// PowerShell requires BOM to properly detect UTF-8 encoding
-			// Without BOM, PowerShell may misinterpret special characters
-			bom := []byte{0xEF, 0xBB, 0xBF}
-			if _, err := tmpScript.Write(bom); err != nil {
-				tmpScript.Close()
-				return errorMsg(fmt.Sprintf("Failed to write BOM: %s", err.Error()))
+	cmd := m.commandAt(idx)
+	m.cursor = idx
+
+	if len(cmd.prompts) > 0 {
+		catName := categoryNameFor(m.categories, e.catIdx)
+		m.input = newInputStateWithHistory(cmd.prompts, fieldHistoryFor(m.history, catName, cmd.title, cmd.prompts))
+		return m, nil
+	}
+
+	if cmd.destructive {
+		m.challenge = newChallengeState(cmd)
+		return m, tickSpinner()
+	}
+
+	return m.startExecution(cmd, nil, nil)
+}
+
+// fieldHistoryFor collects, for each of cmd's prompt fields, the prior
+// values submitted for that field the last times catName/title ran,
+// newest first. It's the source of the Up/Down cycling in the prompt form.
+func fieldHistoryFor(entries []history.Entry, catName, title string, fields []inputField) [][]string {
+	out := make([][]string, len(fields))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Category != catName || e.Title != title {
+			continue
+		}
+		for fi := range fields {
+			if fi < len(e.Values) && e.Values[fi] != "" {
+				out[fi] = append(out[fi], e.Values[fi])
 			}
-			// Write the actual script content
-			if _, err := tmpScript.WriteString(powershellScript); err != nil {
-				tmpScript.Close()
-				return errorMsg(fmt.Sprintf("Failed to write temporary script: %s", err.Error()))
+		}
+	}
+	return out
+}
+
+// challengeArmDelay is how long a destructive-command challenge waits before
+// Enter is honored, so a reflexive keypress from the menu can't carry through
+// into confirming a destructive run.
+const challengeArmDelay = 2 * time.Second
+
+// challengeState tracks an in-progress destructive-command confirmation: the
+// user must type a phrase derived from the command (or the environment's
+// cluster/account identity, when set) before the run is armed.
+type challengeState struct {
+	cmd       command
+	phrase    string
+	input     *inputState
+	startedAt time.Time
+}
+
+// newChallengeState builds a challenge for cmd, deriving its phrase and
+// starting the arm-delay countdown.
+func newChallengeState(cmd command) *challengeState {
+	return &challengeState{
+		cmd:       cmd,
+		phrase:    challengePhrase(cmd),
+		input:     newInputState([]inputField{{label: "Type to confirm", required: true}}),
+		startedAt: time.Now(),
+	}
+}
+
+// challengePhrase derives the phrase a user must type to run a destructive
+// command. When the environment identifies a real cluster or AWS account,
+// that's what's actually at stake and is used instead, so operators are
+// forced to notice which cluster they're about to act on.
+func challengePhrase(cmd command) string {
+	if cluster := os.Getenv("CLUSTER_NAME"); cluster != "" {
+		return cluster
+	}
+	if acct := os.Getenv("AWS_ACCOUNT_ID"); acct != "" {
+		return acct
+	}
+	return "delete " + slugify(cmd.title)
+}
+
+// armed reports whether the challenge's countdown has elapsed and Enter can
+// now be honored.
+func (c *challengeState) armed() bool {
+	return time.Since(c.startedAt) >= challengeArmDelay
+}
+
+// updateChallenge handles keypresses while a destructive-command typing
+// challenge is open: free text editing of the typed phrase, a countdown gate
+// on Enter, and a case-sensitive, exact-match comparison against the
+// challenge phrase.
+func (m model) updateChallenge(msg tea.Msg) (tea.Model, tea.Cmd) {
+	ch := m.challenge
+
+	switch msg := msg.(type) {
+	case tickMsg:
+		if ch.armed() {
+			return m, nil
+		}
+		return m, tickSpinner()
+	case tea.KeyPressMsg:
+		if msg.Code == 'c' && msg.Mod == tea.ModCtrl {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		switch msg.Code {
+		case tea.KeyEscape:
+			m.challenge = nil
+		case tea.KeyEnter:
+			if !ch.armed() {
+				return m, nil
 			}
-			tmpScript.Close()
-			
-			// Launch PowerShell in a new window with the temporary script
-			// Arguments:
-			//   -NoExit: Keep the window open after script execution (handled by our script's ReadKey)
-			//   -ExecutionPolicy Bypass: Skip execution policy checks (needed for temp scripts)
-			//   -File: Execute the script file
-			scriptPath := strings.ReplaceAll(tmpScript.Name(), "'", "''")
-			startProcessCmd := fmt.Sprintf(
-				"Start-Process powershell -ArgumentList '-NoExit', '-ExecutionPolicy', 'Bypass', '-File', '%s'",
-				scriptPath)
-			
-			execCmd := exec.Command("powershell", "-Command", startProcessCmd)
-
-			// Execute the command to open PowerShell window
-			// This returns immediately - the actual script execution happens in the new window
-			if err := execCmd.Run(); err != nil {
-				return errorMsg(fmt.Sprintf("Failed to open PowerShell window: %s", err.Error()))
+			ch.input.attempted = true
+			if ch.input.values[0] != ch.phrase {
+				return m, nil
 			}
-		} else {
-			return errorMsg(fmt.Sprintf("Terminal execution is currently only supported on macOS and Windows. Detected OS: %s", runtime.GOOS))
+			cmd := ch.cmd
+			m.challenge = nil
+			return m.startExecution(cmd, nil, nil)
+		default:
+			ch.input.editActive(msg)
 		}
-
-		// Return success message
-		return outputMsg(fmt.Sprintf("✅ Command opened in new terminal window\n\nScript: %s\nWorking directory: %s\n\nCheck the terminal window for output.", scriptPath, workingDir))
 	}
+
+	return m, nil
 }
 
-// outputMsg and errorMsg are message types used by Bubbletea to communicate
-// command execution results from async operations back to the Update function.
-type outputMsg string
-type errorMsg string
+// updateInput handles keypresses while a prompt form is open: field
+// navigation, text editing, and submission.
+func (m model) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	inp := m.input
+
+	if key.Code == 'c' && key.Mod == tea.ModCtrl {
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	switch key.Code {
+	case tea.KeyEscape:
+		m.input = nil
+		return m, nil
+	case tea.KeyTab:
+		n := len(inp.fields)
+		if key.Mod == tea.ModShift {
+			inp.active = (inp.active - 1 + n) % n
+		} else {
+			inp.active = (inp.active + 1) % n
+		}
+		inp.cursor = len([]rune(inp.values[inp.active]))
+	case tea.KeyEnter:
+		if inp.active < len(inp.fields)-1 {
+			inp.active++
+			inp.cursor = len([]rune(inp.values[inp.active]))
+			return m, nil
+		}
+		inp.attempted = true
+		for i := range inp.fields {
+			if inp.fieldError(i) != "" {
+				return m, nil
+			}
+		}
+		cmd := m.commandAt(m.cursor)
+		args := buildArgsFromInput(cmd, inp)
+		values := append([]string{}, inp.values...)
+		m.input = nil
+		return m.startExecution(cmd, args, values)
+	default:
+		inp.editActive(key)
+	}
+
+	return m, nil
+}
 
 // View renders the current state of the TUI to the terminal.
 // This function is called by Bubbletea whenever the model state changes.
-//
-// The view has three modes:
-//   1. Quitting: Simple goodbye message
-//   2. Executing: Shows command execution status with output/error messages
-//   3. Menu: Displays the interactive command menu with navigation
-//
-// Returns the formatted string that will be displayed in the terminal.
-func (m model) View() string {
-	// Quitting state: Show goodbye message
+func (m model) View() tea.View {
 	if m.quitting {
-		return "\n  See you later!\n\n"
+		return tea.View{Content: "\n  See you later!\n\n"}
+	}
+
+	if m.challenge != nil {
+		return tea.View{Content: m.viewChallenge(), AltScreen: true}
 	}
 
-	// Executing state: Show command execution status
 	if m.executing {
-		var view strings.Builder
-		view.WriteString(titleStyle.Render("OpenEMR on EKS Console"))
-		view.WriteString("\n\n")
-		view.WriteString(itemStyle.Render("Executing: " + m.commands[m.selected].title))
-		view.WriteString("\n\n")
-
-		// Display error message if command failed
-		if m.error != "" {
-			view.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Render("❌ Error:\n"))
-			view.WriteString("\n")
-			// Write error output directly (may contain ANSI codes from script output)
-			view.WriteString(m.error)
-			view.WriteString("\n\n")
-		} else if m.output != "" {
-			// Display success message with output
-			view.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Bold(true).Render("✅ Output:\n"))
-			view.WriteString("\n")
-			// Limit output display to last 100 lines to prevent overwhelming the screen
-			// This is important because some scripts produce large amounts of output
-			lines := strings.Split(m.output, "\n")
-			start := 0
-			if len(lines) > 100 {
-				start = len(lines) - 100
-				view.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Italic(true).Render("(Showing last 100 lines of output)\n\n"))
-			}
-			// Preserve ANSI color codes from script output - write raw output
-			// This allows scripts' color codes (from tools like kubectl, terraform, etc.)
-			// to display properly in the TUI
-			view.WriteString(strings.Join(lines[start:], "\n"))
-			view.WriteString("\n\n")
+		return tea.View{Content: m.viewExecuting(), AltScreen: true}
+	}
+
+	if m.input != nil {
+		return tea.View{Content: m.viewInput(), AltScreen: true}
+	}
+
+	if m.search != nil {
+		return tea.View{Content: m.viewSearch(), AltScreen: true}
+	}
+
+	if m.historyBrowser != nil {
+		return tea.View{Content: m.viewHistoryBrowser(), AltScreen: true}
+	}
+
+	if m.showHelp {
+		return tea.View{Content: m.viewHelp(), AltScreen: true}
+	}
+
+	return tea.View{Content: m.viewMenu(), AltScreen: true}
+}
+
+// viewChallenge renders the destructive-command typing challenge: the
+// command being confirmed, the phrase the user must type verbatim, a
+// countdown before Enter is armed, and their in-progress input.
+func (m model) viewChallenge() string {
+	ch := m.challenge
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(dangerStyle.Render("⚠ DESTRUCTIVE COMMAND"))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render(ch.cmd.title))
+	v.WriteString("\n")
+	v.WriteString(descStyle.Render(ch.cmd.description))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render(fmt.Sprintf("Type %q to confirm:", ch.phrase)))
+	v.WriteString("\n")
+	v.WriteString(itemStyle.Render("> " + ch.input.values[0]))
+	v.WriteString("\n\n")
+
+	if ch.input.attempted && ch.input.values[0] != ch.phrase {
+		v.WriteString(dangerStyle.Render("Input did not match — try again"))
+		v.WriteString("\n\n")
+	}
+
+	if !ch.armed() {
+		remaining := challengeArmDelay - time.Since(ch.startedAt)
+		v.WriteString(helpStyle.Render(fmt.Sprintf("Confirmation arms in %.0fs", remaining.Seconds())))
+		v.WriteString("\n")
+	}
+
+	v.WriteString(helpStyle.Render("Enter: Confirm  Esc: Cancel"))
+	return v.String()
+}
+
+// spinnerFrames animates the "running" indicator in the executing view.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// stdoutLineStyle and stderrLineStyle color-code streamed output by the
+// stream it came from, so stderr noise stands out from normal progress.
+var (
+	stdoutLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	stderrLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+)
+
+// viewExecuting renders the streaming log pane: a scrollable, ring-buffered
+// viewport over the run's captured output, with a spinner and elapsed time
+// while it's still running, or the exit code once it's done.
+func (m model) viewExecuting() string {
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render("Executing: " + m.commandAt(m.selected).title))
+	v.WriteString("\n\n")
+
+	elapsed := time.Since(m.execStart).Round(time.Second)
+	switch {
+	case !m.execDone:
+		frame := spinnerFrames[m.spinnerFrame%len(spinnerFrames)]
+		v.WriteString(descStyle.Render(fmt.Sprintf("%s Running... (%s)", frame, elapsed)))
+	case m.execCancelled:
+		v.WriteString(dangerStyle.Render(fmt.Sprintf("⚠ Cancelled after %s", elapsed)))
+	case m.execExitCode == 0:
+		v.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Bold(true).Render(fmt.Sprintf("✅ Finished in %s", elapsed)))
+	default:
+		v.WriteString(dangerStyle.Render(fmt.Sprintf("❌ Exited with code %d after %s", m.execExitCode, elapsed)))
+	}
+	v.WriteString("\n\n")
+
+	end := len(m.logLines) - m.logScroll
+	start := end - logViewportLines
+	if start < 0 {
+		start = 0
+	}
+	if len(m.logLines) == 0 {
+		v.WriteString(descStyle.Render("(no output yet)"))
+		v.WriteString("\n")
+	}
+	for _, l := range m.logLines[start:end] {
+		style := stdoutLineStyle
+		if l.stream == "stderr" {
+			style = stderrLineStyle
+		}
+		v.WriteString(ansi.RenderOn(l.text, style))
+		v.WriteString("\n")
+	}
+	if m.logScroll > 0 {
+		v.WriteString(descStyle.Render(fmt.Sprintf("\n(scrolled, %d lines below)", m.logScroll)))
+		v.WriteString("\n")
+	}
+
+	if m.transcriptMsg != "" {
+		v.WriteString(descStyle.Render(m.transcriptMsg))
+		v.WriteString("\n")
+	}
+
+	v.WriteString("\n")
+	if m.execDone {
+		v.WriteString(helpStyle.Render("PgUp/PgDown/Home/End: Scroll  s: Save transcript  Enter/Esc/Ctrl+C: Return to menu"))
+	} else {
+		v.WriteString(helpStyle.Render("PgUp/PgDown/Home/End: Scroll  s: Save transcript  Ctrl+C: Cancel"))
+	}
+	return v.String()
+}
+
+func (m model) viewInput() string {
+	cmd := m.commandAt(m.cursor)
+	inp := m.input
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render(cmd.title))
+	v.WriteString("\n\n")
+
+	for i, f := range inp.fields {
+		marker := "optional"
+		if f.required {
+			marker = "*"
+		}
+		label := fmt.Sprintf("%s (%s)", f.label, marker)
+		if i == inp.active {
+			v.WriteString(selectedStyle.Render(label))
 		} else {
-			// Command is running but no output yet
-			view.WriteString(descStyle.Render("⏳ Running command..."))
-			view.WriteString("\n\n")
+			v.WriteString(itemStyle.Render(label))
 		}
+		v.WriteString("\n")
 
-		view.WriteString(helpStyle.Render("Press Enter, Esc, or Ctrl+C to return to menu"))
-		return view.String()
+		display := inp.values[i]
+		if display == "" && i != inp.active {
+			display = descStyle.Render(f.placeholder)
+		}
+		prefix := "> "
+		if f.validator != nil && f.validator.kind == "enum" {
+			prefix = "◀ "
+			display += " ▶"
+		}
+		v.WriteString(itemStyle.Render(prefix + display))
+		if i == inp.active && i < len(inp.fieldHistory) && len(inp.fieldHistory[i]) > 0 {
+			v.WriteString(descStyle.Render("  ↑/↓ prior values"))
+		}
+		v.WriteString("\n\n")
+	}
+
+	if inp.attempted {
+		for i := range inp.fields {
+			if msg := inp.fieldError(i); msg != "" {
+				v.WriteString(dangerStyle.Render(msg + "\n"))
+			}
+		}
+		v.WriteString("\n")
 	}
 
-	// Menu state: Display the interactive command menu
+	v.WriteString(helpStyle.Render("Tab/Shift+Tab: Move between fields  Enter: Next/Submit  Esc: Cancel"))
+	return v.String()
+}
+
+func (m model) viewHelp() string {
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render("Keyboard shortcuts"))
+	v.WriteString("\n\n")
+	v.WriteString(descStyle.Render("↑/k: Up    ↓/j: Down\n"))
+	v.WriteString(descStyle.Render("g: First item    G: Last item\n"))
+	v.WriteString(descStyle.Render("/: Fuzzy search\n"))
+	v.WriteString(descStyle.Render("r: Re-run last command\n"))
+	v.WriteString(descStyle.Render("h: Browse run history (re-run or diff past runs)\n"))
+	v.WriteString(descStyle.Render("Enter: Execute selected command\n"))
+	v.WriteString(descStyle.Render("?: Toggle this help\n"))
+	v.WriteString(descStyle.Render("q/Esc/Ctrl+C: Quit\n"))
+	v.WriteString("\n")
+	v.WriteString(helpStyle.Render("Esc: Close help"))
+	return v.String()
+}
+
+func (m model) viewMenu() string {
 	var s strings.Builder
-	s.WriteString(titleStyle.Render("OpenEMR on EKS Console"))
+	s.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
 	s.WriteString("\n\n")
 
-	// Render each command in the menu
-	for i, cmd := range m.commands {
-		// Determine cursor symbol: ">" for selected item, " " for others
+	for i, e := range m.flatIndex {
+		if e.isCategory {
+			cat := m.categories[e.catIdx]
+			s.WriteString(categoryStyle.Render(fmt.Sprintf("%s %s", cat.icon, cat.name)))
+			s.WriteString("\n")
+			continue
+		}
+
+		cmd := m.categories[e.catIdx].commands[e.cmdIdx]
 		cursor := " "
+		title := cmd.title
+		if cmd.destructive {
+			title += " ⚠"
+		}
+		if cmd.execMode == modeNewWindow {
+			title += " 🗔"
+		}
 		if m.cursor == i {
 			cursor = ">"
-			// Selected item: Use highlighted style
-			s.WriteString(selectedStyle.Render(fmt.Sprintf("%s %s", cursor, cmd.title)))
+			s.WriteString(selectedStyle.Render(fmt.Sprintf("%s %s", cursor, title)))
 		} else {
-			// Unselected item: Use normal style
-			s.WriteString(itemStyle.Render(fmt.Sprintf("%s %s", cursor, cmd.title)))
+			s.WriteString(itemStyle.Render(fmt.Sprintf("%s %s", cursor, title)))
 		}
 		s.WriteString("\n")
-		
-		// Display command description
 		s.WriteString(descStyle.Render(cmd.description))
 		s.WriteString("\n")
-		
-		// Display script path (relative to project root for cleaner display)
-		// Convert absolute path to relative path if possible
+
 		scriptPath := cmd.script
 		if absPath, err := filepath.Abs(cmd.script); err == nil {
 			if relPath, err := filepath.Rel(m.projectRoot, absPath); err == nil {
 				scriptPath = relPath
 			}
 		}
-		
-		// Format script path with arguments if any
 		scriptDisplay := scriptPath
 		if len(cmd.args) > 0 {
 			scriptDisplay = fmt.Sprintf("%s %s", scriptPath, strings.Join(cmd.args, " "))
@@ -866,20 +1578,40 @@ func (m model) View() string {
 		s.WriteString("\n\n")
 	}
 
-	// Display help text at the bottom
-	s.WriteString(helpStyle.Render("↑/↓: Navigate  Enter: Execute  Esc/Ctrl+C: Quit"))
+	if m.launchMessage != "" {
+		s.WriteString(descStyle.Render(m.launchMessage))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render(fmt.Sprintf("%d/%d  ↑/↓: Navigate  Enter: Execute  /: Search  h: History  ?: Help  Esc/Ctrl+C: Quit", m.commandPosition(), m.cmdCount)))
 	return s.String()
 }
 
 // main is the entry point of the application.
 // It initializes the Bubbletea program with the initial model and starts the TUI.
 //
-// tea.WithAltScreen() enables the alternate screen buffer, which:
+// The alternate screen buffer is enabled per-state via View()'s
+// tea.View.AltScreen field rather than a NewProgram option, which:
 //   - Clears the terminal when the program starts
 //   - Restores the original terminal state when the program exits
 //   - Provides a cleaner user experience
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	enableVirtualTerminalProcessing()
+
+	args := parseConfigFlag(os.Args[1:])
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "--validate-catalog":
+			os.Exit(runValidateCatalog())
+		case "--dump-config":
+			os.Exit(dumpConfig())
+		case "run", "list", "describe", "validate", "completion", "install-wrappers":
+			os.Exit(runCLI(args))
+		}
+	}
+
+	p := tea.NewProgram(initialModel())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(1)
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+
+	"github.com/openemr/openemr-on-eks/console/history"
 )
 
 // ── Test helpers ────────────────────────────────────────────────────────
@@ -61,6 +63,10 @@ func keyMsg(s string) tea.Msg {
 		return tea.KeyPressMsg{Code: tea.KeyUp}
 	case "down":
 		return tea.KeyPressMsg{Code: tea.KeyDown}
+	case "left":
+		return tea.KeyPressMsg{Code: tea.KeyLeft}
+	case "right":
+		return tea.KeyPressMsg{Code: tea.KeyRight}
 	case "enter":
 		return tea.KeyPressMsg{Code: tea.KeyEnter}
 	case "esc":
@@ -493,65 +499,188 @@ func TestUpdateEnterOnDestructiveConfirms(t *testing.T) {
 	m.cursor = 5
 	updated, _ := m.Update(keyMsg("enter"))
 	m2 := updated.(model)
-	if !m2.confirming {
-		t.Error("enter on destructive command should set confirming=true")
+	if m2.challenge == nil {
+		t.Fatal("enter on destructive command should open a challenge")
 	}
 	if m2.executing {
-		t.Error("should not be executing yet during confirmation")
+		t.Error("should not be executing yet during the challenge")
 	}
 }
 
-func TestUpdateConfirmCancel(t *testing.T) {
+func TestUpdateChallengeEscCancels(t *testing.T) {
 	m := testModel()
 	m.cursor = 5
-	m.confirming = true
+	m.challenge = newChallengeState(m.commandAt(5))
 
-	updated, _ := m.Update(keyMsg("n"))
+	updated, _ := m.Update(keyMsg("esc"))
 	m2 := updated.(model)
-	if m2.confirming {
-		t.Error("any key other than Y should cancel confirmation")
+	if m2.challenge != nil {
+		t.Error("esc should cancel the challenge")
 	}
 }
 
-// ── Update: output/error messages ───────────────────────────────────────
+func TestUpdateChallengeEnterIgnoredBeforeArmed(t *testing.T) {
+	m := testModel()
+	m.cursor = 5
+	m.challenge = newChallengeState(m.commandAt(5))
+	m.challenge.input.values[0] = m.challenge.phrase
 
-func TestUpdateOutputMsg(t *testing.T) {
+	updated, _ := m.Update(keyMsg("enter"))
+	m2 := updated.(model)
+	if m2.executing {
+		t.Error("enter should be ignored before the challenge is armed, even with correct text")
+	}
+	if m2.challenge == nil {
+		t.Error("challenge should remain open while unarmed")
+	}
+}
+
+func TestUpdateChallengeTypingLowercaseC(t *testing.T) {
 	m := testModel()
-	m.executing = true
+	m.cursor = 5
+	m.challenge = newChallengeState(m.commandAt(5))
 
-	updated, _ := m.Update(outputMsg("done"))
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	m2 := updated.(model)
+	if m2.challenge.input.values[0] != "c" {
+		t.Errorf("expected typed phrase %q, got %q", "c", m2.challenge.input.values[0])
+	}
+}
+
+func TestUpdateChallengeMismatchDoesNotExecute(t *testing.T) {
+	m := testModel()
+	m.cursor = 5
+	m.challenge = newChallengeState(m.commandAt(5))
+	m.challenge.startedAt = m.challenge.startedAt.Add(-challengeArmDelay)
+	m.challenge.input.values[0] = strings.ToUpper(m.challenge.phrase)
+
+	updated, _ := m.Update(keyMsg("enter"))
 	m2 := updated.(model)
 	if m2.executing {
-		t.Error("outputMsg should clear executing")
+		t.Error("a mismatched (or wrong-case) phrase should not start execution")
 	}
-	if m2.output != "done" {
-		t.Errorf("output = %q, want %q", m2.output, "done")
+	if m2.challenge == nil || !m2.challenge.input.attempted {
+		t.Error("a failed attempt should mark the challenge input as attempted")
 	}
 }
 
-func TestUpdateErrorMsg(t *testing.T) {
+func TestUpdateChallengeMatchArmedStartsExecution(t *testing.T) {
 	m := testModel()
-	m.executing = true
+	m.cursor = 5
+	m.challenge = newChallengeState(m.commandAt(5))
+	m.challenge.startedAt = m.challenge.startedAt.Add(-challengeArmDelay)
+	m.challenge.input.values[0] = m.challenge.phrase
+
+	updated, _ := m.Update(keyMsg("enter"))
+	m2 := updated.(model)
+	if !m2.executing {
+		t.Error("the exact phrase, once armed, should start execution")
+	}
+	if m2.challenge != nil {
+		t.Error("the challenge should be cleared once execution starts")
+	}
+}
 
-	updated, _ := m.Update(errorMsg("fail"))
+func TestActivateNonDestructiveSkipsChallenge(t *testing.T) {
+	m := testModel()
+	updated, _ := m.activate(m.cursor)
 	m2 := updated.(model)
+	if m2.challenge != nil {
+		t.Error("a non-destructive command should go straight to execution")
+	}
+	if !m2.executing {
+		t.Error("expected execution to start immediately")
+	}
+}
+
+func TestStartExecutionNewWindowModeSkipsExecutingView(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	m := testModel()
+	cmd := command{title: "Open Console", script: "/tmp/open-console.sh", execMode: modeNewWindow}
+	updated, _ := m.startExecution(cmd, nil, nil)
+	m2 := updated.(model)
+
 	if m2.executing {
-		t.Error("errorMsg should clear executing")
+		t.Error("a new-window command should never enter the streaming executing view")
+	}
+	if m2.launchMessage == "" {
+		t.Error("expected a launchMessage describing the launch attempt")
+	}
+}
+
+// ── Update: streamed output ──────────────────────────────────────────────
+
+func TestUpdateLogLineMsgAppendsLine(t *testing.T) {
+	m := testModel()
+	m.executing = true
+	m.execCh = make(chan tea.Msg, 1)
+
+	updated, _ := m.Update(logLineMsg{stream: "stdout", text: "hello"})
+	m2 := updated.(model)
+	if !m2.executing {
+		t.Error("a log line should not end execution")
+	}
+	if len(m2.logLines) != 1 || m2.logLines[0].text != "hello" {
+		t.Errorf("expected the line to be buffered, got %+v", m2.logLines)
+	}
+}
+
+func TestUpdateFinishMsgMarksDone(t *testing.T) {
+	m := testModel()
+	m.executing = true
+
+	updated, _ := m.Update(finishMsg{exitCode: 1})
+	m2 := updated.(model)
+	if !m2.executing {
+		t.Error("finishMsg should keep the log pane open until dismissed")
+	}
+	if !m2.execDone {
+		t.Error("finishMsg should set execDone")
 	}
-	if m2.error != "fail" {
-		t.Errorf("error = %q, want %q", m2.error, "fail")
+	if m2.execExitCode != 1 {
+		t.Errorf("execExitCode = %d, want 1", m2.execExitCode)
 	}
 }
 
 func TestUpdateExecutingDismiss(t *testing.T) {
 	m := testModel()
 	m.executing = true
-	m.output = "some output"
+	m.execDone = true
 
 	updated, _ := m.Update(keyMsg("enter"))
 	m2 := updated.(model)
 	if m2.executing {
-		t.Error("enter during execution should dismiss")
+		t.Error("enter after the run is done should dismiss the pane")
+	}
+}
+
+func TestUpdateExecutingEnterIgnoredWhileRunning(t *testing.T) {
+	m := testModel()
+	m.executing = true
+	m.execDone = false
+
+	updated, _ := m.Update(keyMsg("enter"))
+	m2 := updated.(model)
+	if !m2.executing {
+		t.Error("enter should not dismiss a still-running execution")
+	}
+}
+
+func TestUpdateExecutingSavesTranscriptOnS(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	m := testModel()
+	m.executing = true
+	m.execDone = true
+	m.logLines = []logLine{{stream: "stdout", text: "done"}}
+
+	updated, _ := m.Update(keyMsg("s"))
+	m2 := updated.(model)
+	if m2.transcriptMsg == "" || !strings.Contains(m2.transcriptMsg, "saved transcript to") {
+		t.Errorf("expected a saved-transcript status message, got %q", m2.transcriptMsg)
 	}
 }
 
@@ -615,35 +744,45 @@ func TestViewQuitting(t *testing.T) {
 	}
 }
 
-func TestViewConfirming(t *testing.T) {
+func TestViewChallenge(t *testing.T) {
 	m := testModel()
 	m.cursor = 5 // destructive command
-	m.confirming = true
+	m.challenge = newChallengeState(m.commandAt(5))
 	v := m.View()
 	if !strings.Contains(v.Content, "DESTRUCTIVE") {
-		t.Error("confirming view should contain DESTRUCTIVE warning")
+		t.Error("challenge view should contain DESTRUCTIVE warning")
+	}
+	if !strings.Contains(v.Content, m.challenge.phrase) {
+		t.Error("challenge view should show the phrase to type")
 	}
 }
 
 func TestViewExecutingWithOutput(t *testing.T) {
 	m := testModel()
 	m.cursor = 1
+	m.selected = 1
 	m.executing = true
-	m.output = "execution output here"
+	m.logLines = []logLine{{stream: "stdout", text: "execution output here"}}
 	v := m.View()
 	if !strings.Contains(v.Content, "execution output here") {
-		t.Error("executing view should show output")
+		t.Error("executing view should show streamed output")
 	}
 }
 
 func TestViewExecutingWithError(t *testing.T) {
 	m := testModel()
 	m.cursor = 1
+	m.selected = 1
 	m.executing = true
-	m.error = "something broke"
+	m.execDone = true
+	m.execExitCode = 1
+	m.logLines = []logLine{{stream: "stderr", text: "something broke"}}
 	v := m.View()
 	if !strings.Contains(v.Content, "something broke") {
-		t.Error("executing view should show error")
+		t.Error("executing view should show stderr output")
+	}
+	if !strings.Contains(v.Content, "Exited with code 1") {
+		t.Error("executing view should show the exit code once done")
 	}
 }
 
@@ -692,7 +831,7 @@ func TestModelStartsCursorOnCommand(t *testing.T) {
 
 func TestModelStartsNotQuitting(t *testing.T) {
 	m := testModel()
-	if m.quitting || m.executing || m.confirming || m.showHelp {
+	if m.quitting || m.executing || m.challenge != nil || m.showHelp {
 		t.Error("model should start in default idle state")
 	}
 }
@@ -982,6 +1121,52 @@ func TestInputSubmitRequiredEmpty(t *testing.T) {
 	}
 }
 
+func TestInputSubmitFailsValidator(t *testing.T) {
+	v := newEnumValidator([]string{"us-east-1", "us-west-2"})
+	fields := []inputField{{label: "Region", required: true, validator: v}}
+	m := testModelWithPrompts()
+	m.input = newInputState(fields)
+	m.input.values[0] = "eu-central-1" // not a listed choice
+
+	updated, _ := m.Update(keyMsg("enter"))
+	m2 := updated.(model)
+	if m2.input == nil {
+		t.Fatal("input should remain open when the validator rejects the value")
+	}
+	if !m2.input.attempted {
+		t.Error("attempted should be set to true after a failed submit")
+	}
+}
+
+func TestEnumFieldStartsOnFirstChoiceAndCycles(t *testing.T) {
+	v := newEnumValidator([]string{"7.0", "7.0.1", "7.0.2"})
+	inp := newInputState([]inputField{{label: "Version", validator: v}})
+	if inp.values[0] != "7.0" {
+		t.Errorf("expected enum field to default to its first choice, got %q", inp.values[0])
+	}
+
+	inp.editActive(keyMsg("right").(tea.KeyPressMsg))
+	if inp.values[0] != "7.0.1" {
+		t.Errorf("expected right to cycle forward, got %q", inp.values[0])
+	}
+	inp.editActive(keyMsg("left").(tea.KeyPressMsg))
+	if inp.values[0] != "7.0" {
+		t.Errorf("expected left to cycle back, got %q", inp.values[0])
+	}
+}
+
+func TestEnumFieldIgnoresTextEntry(t *testing.T) {
+	v := newEnumValidator([]string{"a", "b"})
+	inp := newInputState([]inputField{{label: "Pick", validator: v}})
+	handled := inp.editActive(keyMsg("x").(tea.KeyPressMsg))
+	if handled {
+		t.Error("expected enum fields to ignore free-text keys")
+	}
+	if inp.values[0] != "a" {
+		t.Errorf("expected value to be unchanged, got %q", inp.values[0])
+	}
+}
+
 func TestInputSubmitSuccess(t *testing.T) {
 	m := testModelWithPrompts()
 	m.cursor = 2 // "WithPrompts"
@@ -1057,10 +1242,17 @@ func TestBuildArgsEmptyFieldSkipped(t *testing.T) {
 	}
 }
 
-func TestBuildArgsRestoreLatestSnapshot(t *testing.T) {
+func testFieldsSnapshotEmptyAdds() []inputField {
+	return []inputField{
+		{label: "Bucket", required: true},
+		{label: "Snapshot", required: false, emptyAdds: "--latest-snapshot"},
+	}
+}
+
+func TestBuildArgsEmptyAddsOnBlankField(t *testing.T) {
 	cmd := command{script: "/tmp/scripts/restore.sh"}
 	inp := &inputState{
-		fields: testFieldsRequired(),
+		fields: testFieldsSnapshotEmptyAdds(),
 		values: []string{"my-bucket", ""},
 	}
 	args := buildArgsFromInput(cmd, inp)
@@ -1071,20 +1263,20 @@ func TestBuildArgsRestoreLatestSnapshot(t *testing.T) {
 		}
 	}
 	if !found {
-		t.Errorf("restore.sh with empty snapshot should add --latest-snapshot, got: %v", args)
+		t.Errorf("a blank field with emptyAdds set should add its token, got: %v", args)
 	}
 }
 
-func TestBuildArgsRestoreWithSnapshot(t *testing.T) {
+func TestBuildArgsEmptyAddsSkippedWhenFieldFilled(t *testing.T) {
 	cmd := command{script: "/tmp/scripts/restore.sh"}
 	inp := &inputState{
-		fields: testFieldsRequired(),
+		fields: testFieldsSnapshotEmptyAdds(),
 		values: []string{"my-bucket", "snap-id"},
 	}
 	args := buildArgsFromInput(cmd, inp)
 	for _, a := range args {
 		if a == "--latest-snapshot" {
-			t.Error("restore.sh with explicit snapshot should not add --latest-snapshot")
+			t.Error("a filled field should not trigger its emptyAdds token")
 		}
 	}
 }
@@ -1203,3 +1395,112 @@ func TestViewInputFormShowsValidationError(t *testing.T) {
 		t.Error("input form should show validation error when attempted with empty required field")
 	}
 }
+
+// ── Field history cycling ───────────────────────────────────────────────
+
+func TestFieldHistoryForCollectsPriorValuesNewestFirst(t *testing.T) {
+	entries := []history.Entry{
+		{Category: "Beta", Title: "Cmd3", Values: []string{"bucket-a"}},
+		{Category: "Alpha", Title: "Cmd1", Values: []string{"not-this-one"}},
+		{Category: "Beta", Title: "Cmd3", Values: []string{"bucket-b"}},
+	}
+	hist := fieldHistoryFor(entries, "Beta", "Cmd3", []inputField{{label: "Bucket"}})
+	want := []string{"bucket-b", "bucket-a"}
+	if len(hist) != 1 || len(hist[0]) != len(want) {
+		t.Fatalf("hist = %v, want one field with %v", hist, want)
+	}
+	for i := range want {
+		if hist[0][i] != want[i] {
+			t.Errorf("hist[0][%d] = %q, want %q", i, hist[0][i], want[i])
+		}
+	}
+}
+
+func TestCycleHistoryMovesThroughPriorValuesAndBack(t *testing.T) {
+	inp := newInputStateWithHistory(testFieldsRequired(), [][]string{{"bucket-b", "bucket-a"}})
+	inp.values[0] = "typing-now"
+
+	if !inp.cycleHistory(1) || inp.values[0] != "bucket-b" {
+		t.Fatalf("expected first Up to show the newest prior value, got %q", inp.values[0])
+	}
+	if !inp.cycleHistory(1) || inp.values[0] != "bucket-a" {
+		t.Fatalf("expected second Up to show the older prior value, got %q", inp.values[0])
+	}
+	if !inp.cycleHistory(1) || inp.values[0] != "bucket-a" {
+		t.Errorf("expected Up at the oldest entry to stay put, got %q", inp.values[0])
+	}
+	if !inp.cycleHistory(-1) || inp.values[0] != "bucket-b" {
+		t.Fatalf("expected Down to step back towards the live value, got %q", inp.values[0])
+	}
+	if !inp.cycleHistory(-1) || inp.values[0] != "typing-now" {
+		t.Errorf("expected Down past the newest entry to restore the live value, got %q", inp.values[0])
+	}
+}
+
+func TestCycleHistoryNoOpWithoutHistory(t *testing.T) {
+	inp := newInputState(testFieldsRequired())
+	if inp.cycleHistory(1) {
+		t.Error("expected cycleHistory to report no history for a field with none")
+	}
+}
+
+func TestEditActiveResetsHistoryBrowseOnTyping(t *testing.T) {
+	inp := newInputStateWithHistory(testFieldsRequired(), [][]string{{"bucket-b"}})
+	inp.cycleHistory(1)
+	if inp.histPos[0] != 0 {
+		t.Fatalf("expected histPos to be 0 after browsing, got %d", inp.histPos[0])
+	}
+	inp.editActive(keyMsg("x").(tea.KeyPressMsg))
+	if inp.histPos[0] != -1 {
+		t.Errorf("expected typing to reset history browse to -1, got %d", inp.histPos[0])
+	}
+}
+
+// ── Sensitive-field redaction ────────────────────────────────────────────
+
+func TestRedactSensitiveBlanksMarkedFields(t *testing.T) {
+	fields := []inputField{
+		{label: "Bucket"},
+		{label: "APIKey", sensitive: true},
+	}
+	out := redactSensitive(fields, []string{"my-bucket", "super-secret"})
+	if out[0] != "my-bucket" {
+		t.Errorf("expected the non-sensitive field to pass through, got %q", out[0])
+	}
+	if out[1] != "" {
+		t.Errorf("expected the sensitive field to be redacted, got %q", out[1])
+	}
+}
+
+func TestRedactSensitiveNilValuesStayNil(t *testing.T) {
+	if out := redactSensitive(testFieldsRequired(), nil); out != nil {
+		t.Errorf("expected nil values (no prompts submitted) to stay nil, got %v", out)
+	}
+}
+
+// TestRerunLastReproducesOriginalArgvFromHistory is the round-trip test: a
+// history entry's Args is exactly what buildArgsFromInput would have
+// produced for the same submitted values, so rerunLast reconstructs the
+// identical command line without reopening the prompt form.
+func TestRerunLastReproducesOriginalArgvFromHistory(t *testing.T) {
+	cmd := command{title: "WithPrompts", description: "Has prompts", script: "/tmp/prompted.sh", prompts: testFieldsRequired()}
+
+	inp := newInputState(cmd.prompts)
+	inp.values[0] = "my-bucket"
+	inp.values[1] = "snap-1"
+	wantArgs := buildArgsFromInput(cmd, inp)
+
+	m := testModelWithPrompts()
+	m.history = []history.Entry{{Category: "Test", Title: "WithPrompts", Args: wantArgs, Values: append([]string{}, inp.values...)}}
+
+	updated, _ := m.rerunLast()
+	m2 := updated.(model)
+	if len(m2.execArgs) != len(wantArgs) {
+		t.Fatalf("execArgs = %v, want %v", m2.execArgs, wantArgs)
+	}
+	for i := range wantArgs {
+		if m2.execArgs[i] != wantArgs[i] {
+			t.Errorf("execArgs[%d] = %q, want %q", i, m2.execArgs[i], wantArgs[i])
+		}
+	}
+}
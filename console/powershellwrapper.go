@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PowerShell's own exit code for the outer `powershell.exe` process only
+// tells you whether *PowerShell* completed cleanly, not whether the bash
+// command it shelled out to actually succeeded — a failing WSL/bash
+// invocation can leave the outer process exiting 0 on Windows PowerShell
+// 3/4. powershellBackend works around this the way Jenkins' durable-task
+// plugin does: it generates a small wrapper script that runs the bash
+// invocation itself, captures the *real* outcome in a result file, and lets
+// the Go side read that file back after the process exits instead of
+// trusting the outer exit code.
+
+// powerShellResultFiles returns the deterministic output/log/result file
+// paths a powershellBackend run for script uses to hand its real outcome
+// back to Go. They're derived purely from script's path (not from any
+// random or time-based component) so BuildCommand, which writes the paths
+// into the generated wrapper, and ResolveExitCode, which reads the result
+// file back after the process exits, always agree on where to look without
+// needing to share in-memory state.
+func powerShellResultFiles(script string) (outputFile, logFile, resultFile string) {
+	base := strings.NewReplacer(`\`, "_", "/", "_", ":", "_").Replace(script)
+	dir := filepath.Join(os.TempDir(), "openemr-eks-console")
+	return filepath.Join(dir, base+".out"),
+		filepath.Join(dir, base+".log"),
+		filepath.Join(dir, base+".result")
+}
+
+// buildPowerShellWrapperScript renders the PowerShell source for a wrapper
+// that runs mainScript (a single bash command line, e.g. "bash deploy.sh
+// --yes") through the Execute-AndWriteOutput function: $ErrorActionPreference
+// = 'Stop' so terminating errors actually terminate, stdout teed to
+// outputFile, a full transcript to logFile, and exactly one line written to
+// resultFile describing the outcome — "0" on success, "EXIT:<code>" when
+// the bash invocation exited non-zero, or "ERROR:<message>" when PowerShell
+// itself failed to run it at all (e.g. bash isn't on PATH).
+func buildPowerShellWrapperScript(mainScript, outputFile, logFile, resultFile string) string {
+	return fmt.Sprintf(`[CmdletBinding()]
+param()
+
+function Execute-AndWriteOutput {
+    [CmdletBinding()]
+    param(
+        [Parameter(Mandatory=$true)][string]$MainScript,
+        [Parameter(Mandatory=$true)][string]$OutputFile,
+        [Parameter(Mandatory=$true)][string]$LogFile,
+        [Parameter(Mandatory=$true)][string]$ResultFile,
+        [switch]$CaptureOutput
+    )
+
+    $ErrorActionPreference = 'Stop'
+    New-Item -ItemType Directory -Force -Path (Split-Path $OutputFile) | Out-Null
+    Start-Transcript -Path $LogFile -Force | Out-Null
+    try {
+        Invoke-Expression $MainScript *>&1 | Tee-Object -FilePath $OutputFile
+        $exitCode = $LASTEXITCODE
+        if ($null -eq $exitCode) { $exitCode = 0 }
+        if ($exitCode -ne 0) {
+            Set-Content -Path $ResultFile -Value "EXIT:$exitCode"
+        } else {
+            Set-Content -Path $ResultFile -Value "0"
+        }
+    } catch {
+        Set-Content -Path $ResultFile -Value "ERROR:$($_.Exception.Message)"
+    } finally {
+        Stop-Transcript | Out-Null
+    }
+}
+
+Execute-AndWriteOutput -MainScript %s -OutputFile %s -LogFile %s -ResultFile %s -CaptureOutput
+`, psQuote(mainScript), psQuote(outputFile), psQuote(logFile), psQuote(resultFile))
+}
+
+// psQuote wraps s in single quotes for safe inclusion as a PowerShell string
+// literal, doubling any single quotes it already contains (PowerShell's
+// escaping convention, unlike bash's backslash-escape).
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// resolvePowerShellExitCode reads the result file a powershellBackend run
+// for script wrote (see powerShellResultFiles) and returns the exit code it
+// recorded: 0 or a positive "EXIT:<code>" for the bash invocation's own
+// outcome, or -1 for "ERROR:..." (PowerShell couldn't run it at all, e.g.
+// bash wasn't on PATH). If the result file is missing entirely — the
+// wrapper never got far enough to write one — fallback (the outer
+// powershell.exe process's own exit code) is returned instead, since that's
+// the best information available.
+func resolvePowerShellExitCode(script string, fallback int) int {
+	_, _, resultFile := powerShellResultFiles(script)
+	data, err := os.ReadFile(resultFile)
+	if err != nil {
+		return fallback
+	}
+	content := strings.TrimSpace(string(data))
+
+	switch {
+	case content == "0":
+		return 0
+	case strings.HasPrefix(content, "EXIT:"):
+		if code, err := strconv.Atoi(strings.TrimPrefix(content, "EXIT:")); err == nil {
+			return code
+		}
+		return fallback
+	case strings.HasPrefix(content, "ERROR:"):
+		return -1
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildPowerShellWrapperScriptEmbedsAllFourFiles(t *testing.T) {
+	script := buildPowerShellWrapperScript("bash deploy.sh --yes", "/tmp/out.txt", "/tmp/log.txt", "/tmp/result.txt")
+	if !containsAll(script, "Execute-AndWriteOutput", "'bash deploy.sh --yes'", "'/tmp/out.txt'", "'/tmp/log.txt'", "'/tmp/result.txt'", "$ErrorActionPreference = 'Stop'") {
+		t.Errorf("wrapper script missing expected pieces:\n%s", script)
+	}
+}
+
+func TestPSQuoteEscapesSingleQuotes(t *testing.T) {
+	if got := psQuote(`it's`); got != `'it''s'` {
+		t.Errorf("psQuote(%q) = %q, want %q", `it's`, got, `'it''s'`)
+	}
+}
+
+func TestPowerShellResultFilesAreDeterministic(t *testing.T) {
+	o1, l1, r1 := powerShellResultFiles("/tmp/deploy.sh")
+	o2, l2, r2 := powerShellResultFiles("/tmp/deploy.sh")
+	if o1 != o2 || l1 != l2 || r1 != r2 {
+		t.Error("expected powerShellResultFiles to be deterministic for the same script path")
+	}
+	if o1 == l1 || o1 == r1 || l1 == r1 {
+		t.Error("expected output/log/result paths to be distinct")
+	}
+}
+
+func TestResolvePowerShellExitCodeSuccess(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "ok.sh")
+	_, _, resultFile := powerShellResultFiles(script)
+	writeResultFile(t, resultFile, "0")
+
+	if got := resolvePowerShellExitCode(script, -99); got != 0 {
+		t.Errorf("resolvePowerShellExitCode = %d, want 0", got)
+	}
+}
+
+func TestResolvePowerShellExitCodeScriptFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "fail.sh")
+	_, _, resultFile := powerShellResultFiles(script)
+	writeResultFile(t, resultFile, "EXIT:42")
+
+	if got := resolvePowerShellExitCode(script, -99); got != 42 {
+		t.Errorf("resolvePowerShellExitCode = %d, want 42", got)
+	}
+}
+
+func TestResolvePowerShellExitCodeLauncherError(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "noshell.sh")
+	_, _, resultFile := powerShellResultFiles(script)
+	writeResultFile(t, resultFile, "ERROR:bash is not recognized")
+
+	if got := resolvePowerShellExitCode(script, -99); got != -1 {
+		t.Errorf("resolvePowerShellExitCode = %d, want -1 for a launcher-level error", got)
+	}
+}
+
+func TestResolvePowerShellExitCodeMissingResultFileFallsBack(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "never-ran.sh")
+	if got := resolvePowerShellExitCode(script, 7); got != 7 {
+		t.Errorf("resolvePowerShellExitCode = %d, want fallback 7 when no result file exists", got)
+	}
+}
+
+func writeResultFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating result dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing result file: %v", err)
+	}
+}
+
+func TestBuildPowerShellWrapperScriptIsMultiline(t *testing.T) {
+	script := buildPowerShellWrapperScript("bash a.sh", "/tmp/a.out", "/tmp/a.log", "/tmp/a.result")
+	if !strings.Contains(script, "function Execute-AndWriteOutput") {
+		t.Error("expected the wrapper to define Execute-AndWriteOutput")
+	}
+}
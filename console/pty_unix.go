@@ -0,0 +1,71 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/creack/pty"
+)
+
+// usePTY reports whether streamScript should run the child under a
+// pseudo-terminal instead of separate stdout/stderr pipes. It's opt-in via
+// OPENEMR_EKS_PTY=1: a PTY makes the child think it has an interactive
+// terminal (so scripts that only colorize output when isatty() is true keep
+// their ANSI colors), but it also merges stdout and stderr into a single
+// stream, which the plain-pipe mode above keeps separate.
+func usePTY() bool {
+	return os.Getenv("OPENEMR_EKS_PTY") == "1"
+}
+
+// streamScriptPTY is streamScript's PTY-backed path: it starts execCmd
+// attached to a pseudo-terminal, forwards raw output chunks (not split into
+// lines, so in-progress ANSI escape sequences never get cut in half) as
+// logLineMsg values tagged "stdout", and finishes with the same finishMsg
+// the pipe-based path sends, reusing the Update plumbing rather than adding
+// parallel message types for what is structurally the same two events
+// ("got some output", "the process is done").
+func streamScriptPTY(ctx context.Context, execCmd *exec.Cmd, start time.Time, ch chan tea.Msg) {
+	f, err := pty.Start(execCmd)
+	if err != nil {
+		ch <- logLineMsg{stream: "stderr", text: fmt.Sprintf("Failed to start script under a PTY: %s", err), ts: time.Now()}
+		ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+		return
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	for {
+		n, readErr := f.Read(buf)
+		if n > 0 {
+			ch <- logLineMsg{stream: "stdout", text: string(buf[:n]), ts: time.Now()}
+		}
+		if readErr != nil {
+			// A PTY's read end returns an error (rather than a clean io.EOF)
+			// once the child exits and closes its end; that's expected, not
+			// a real failure, so it just ends the read loop.
+			break
+		}
+	}
+
+	err = execCmd.Wait()
+	exitCode := 0
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else {
+			exitCode = -1
+		}
+	}
+
+	ch <- finishMsg{
+		exitCode:  exitCode,
+		duration:  time.Since(start),
+		cancelled: ctx.Err() != nil,
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "testing"
+
+func TestUsePTYReadsEnvToggle(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_PTY", "")
+	if usePTY() {
+		t.Error("expected usePTY to be false by default")
+	}
+	t.Setenv("OPENEMR_EKS_PTY", "1")
+	if !usePTY() {
+		t.Error("expected usePTY to be true when OPENEMR_EKS_PTY=1")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// usePTY is always false on Windows: github.com/creack/pty doesn't support
+// it, so OPENEMR_EKS_PTY is silently ignored here rather than failing a run
+// that would otherwise work fine over the regular stdout/stderr pipes.
+func usePTY() bool { return false }
+
+// streamScriptPTY is unreachable on Windows since usePTY always returns
+// false, but still needs to exist to satisfy streamScript's call to it.
+func streamScriptPTY(ctx context.Context, execCmd *exec.Cmd, start time.Time, ch chan tea.Msg) {
+	ch <- finishMsg{exitCode: -1, duration: time.Since(start)}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// searchResult is one ranked hit from a fuzzy search: a pointer back to the
+// command's location in the category tree, its score, and the rune
+// positions within its title that matched the query (for highlighting).
+type searchResult struct {
+	catIdx         int
+	cmdIdx         int
+	score          int
+	matchedIndexes []int
+}
+
+// searchState holds an in-progress fuzzy-search session opened with '/'.
+// It keeps its own copy of the category tree so ranking doesn't need to
+// thread the full model through every scoring call.
+type searchState struct {
+	categories []category
+	query      string
+	matches    []searchResult
+	cursor     int // index into matches
+}
+
+// newSearchState opens a fresh search session with no query and every
+// command ranked (in catalog order, since an empty query matches nothing
+// by score but we still want a way to browse).
+func newSearchState(cats []category) *searchState {
+	s := &searchState{categories: cats}
+	s.rebuild()
+	return s
+}
+
+// rebuild recomputes s.matches from the current query.
+func (s *searchState) rebuild() {
+	if s.query == "" {
+		s.matches = nil
+		for ci, cat := range s.categories {
+			for cj := range cat.commands {
+				s.matches = append(s.matches, searchResult{catIdx: ci, cmdIdx: cj})
+			}
+		}
+		s.cursor = 0
+		return
+	}
+
+	var results []searchResult
+	for ci, cat := range s.categories {
+		for cj, cmd := range cat.commands {
+			score, idxs, ok := fuzzyMatch(s.query, cmd.title)
+			if !ok {
+				continue
+			}
+			results = append(results, searchResult{catIdx: ci, cmdIdx: cj, score: score, matchedIndexes: idxs})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	s.matches = results
+	if s.cursor >= len(s.matches) {
+		s.cursor = 0
+	}
+}
+
+// fuzzyMatch reports whether every rune of query appears in target, in
+// order (case-insensitively), and if so returns a score rewarding
+// consecutive runs, word-boundary starts, and prefix matches, while
+// penalizing gaps between matched positions. This mirrors the approach used
+// by sahilm/fuzzy: a simple greedy forward scan rather than full dynamic
+// programming, which is more than good enough for ranking a short command
+// list.
+func fuzzyMatch(query, target string) (score int, matchedIndexes []int, ok bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+
+	q := []rune(strings.ToLower(query))
+	t := []rune(target)
+	tLower := []rune(strings.ToLower(target))
+
+	idxs := make([]int, 0, len(q))
+	qi := 0
+	lastMatch := -1
+
+	for ti := 0; ti < len(tLower) && qi < len(q); ti++ {
+		if tLower[ti] != q[qi] {
+			continue
+		}
+		idxs = append(idxs, ti)
+
+		switch {
+		case lastMatch == ti-1:
+			// Consecutive match: strongly rewarded.
+			score += 15
+		case isWordBoundary(t, ti):
+			// Start of a word (after space/-/_ or a camelCase transition).
+			score += 10
+		default:
+			// Gap penalty, capped so a single distant match isn't disqualifying.
+			gap := ti - lastMatch
+			if gap > 5 {
+				gap = 5
+			}
+			score -= gap
+		}
+
+		if ti == 0 {
+			score += 10 // prefix bonus
+		}
+
+		lastMatch = ti
+		qi++
+	}
+
+	if qi != len(q) {
+		return 0, nil, false
+	}
+	return score, idxs, true
+}
+
+// isWordBoundary reports whether rune index i in target begins a new "word":
+// the very first character, the character right after a space/-/_, or a
+// camelCase transition (lowercase followed by uppercase).
+func isWordBoundary(target []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := target[i-1]
+	if prev == ' ' || prev == '-' || prev == '_' {
+		return true
+	}
+	if unicode.IsLower(prev) && unicode.IsUpper(target[i]) {
+		return true
+	}
+	return false
+}
+
+// updateSearch handles keypresses while fuzzy search is active: typing
+// edits the query, up/down move the result cursor, enter activates the
+// selected command, and esc closes search and restores the normal menu.
+func (m model) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	s := m.search
+
+	if key.Code == 'c' && key.Mod == tea.ModCtrl {
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	switch key.Code {
+	case tea.KeyEscape:
+		m.search = nil
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(s.query); len(r) > 0 {
+			s.query = string(r[:len(r)-1])
+			s.rebuild()
+		}
+	case tea.KeyUp:
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case tea.KeyDown:
+		if s.cursor < len(s.matches)-1 {
+			s.cursor++
+		}
+	case tea.KeyEnter:
+		if len(s.matches) == 0 {
+			return m, nil
+		}
+		hit := s.matches[s.cursor]
+		for i, e := range m.flatIndex {
+			if !e.isCategory && e.catIdx == hit.catIdx && e.cmdIdx == hit.cmdIdx {
+				m.search = nil
+				return m.activate(i)
+			}
+		}
+	default:
+		if key.Text != "" {
+			s.query += key.Text
+			s.rebuild()
+		}
+	}
+
+	return m, nil
+}
+
+// viewSearch renders the fuzzy-search overlay: the query box followed by
+// ranked results with matched runes highlighted.
+func (m model) viewSearch() string {
+	s := m.search
+	var v strings.Builder
+	v.WriteString(titleStyle.Render("OpenEMR on EKS Console " + version))
+	v.WriteString("\n\n")
+	v.WriteString(itemStyle.Render("Search: " + s.query + "▏"))
+	v.WriteString("\n\n")
+
+	if len(s.matches) == 0 {
+		v.WriteString(descStyle.Render("No matches"))
+		v.WriteString("\n\n")
+	}
+
+	for i, res := range s.matches {
+		cmd := m.categories[res.catIdx].commands[res.cmdIdx]
+		line := renderMatchedTitle(cmd.title, res.matchedIndexes)
+		prefix := "  "
+		if i == s.cursor {
+			prefix = "> "
+			line = selectedStyle.Render(prefix + line)
+		} else {
+			line = itemStyle.Render(prefix + line)
+		}
+		v.WriteString(line)
+		v.WriteString("\n")
+	}
+
+	v.WriteString("\n")
+	v.WriteString(helpStyle.Render("Type to search  ↑/↓: Navigate  Enter: Run  Esc: Close"))
+	return v.String()
+}
+
+// renderMatchedTitle renders a command title with the runes at matched
+// positions styled bold/underlined, leaving the rest unstyled.
+func renderMatchedTitle(title string, matched []int) string {
+	if len(matched) == 0 {
+		return title
+	}
+	matchSet := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		matchSet[i] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(title) {
+		if matchSet[i] {
+			b.WriteString(matchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
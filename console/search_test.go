@@ -0,0 +1,181 @@
+package main
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestFuzzyMatchPrefixBeatsSubstring(t *testing.T) {
+	prefixScore, _, ok := fuzzyMatch("dep", "Deploy Training Setup")
+	if !ok {
+		t.Fatal("expected prefix match to succeed")
+	}
+	substrScore, _, ok := fuzzyMatch("dep", "Quick Deploy")
+	if !ok {
+		t.Fatal("expected substring match to succeed")
+	}
+	if prefixScore <= substrScore {
+		t.Errorf("prefix match score %d should beat substring match score %d", prefixScore, substrScore)
+	}
+}
+
+func TestFuzzyMatchConsecutiveBeatsScattered(t *testing.T) {
+	consecutive, _, ok := fuzzyMatch("dep", "Quick Deploy")
+	if !ok {
+		t.Fatal("expected consecutive match to succeed")
+	}
+	scattered, _, ok := fuzzyMatch("dpy", "Quick Deploy")
+	if !ok {
+		t.Fatal("expected scattered match to succeed")
+	}
+	if consecutive <= scattered {
+		t.Errorf("consecutive match score %d should beat scattered match score %d", consecutive, scattered)
+	}
+}
+
+func TestFuzzyMatchWordBoundary(t *testing.T) {
+	score, idxs, ok := fuzzyMatch("cv", "Check Versions")
+	if !ok {
+		t.Fatal("expected word-boundary match to succeed")
+	}
+	if len(idxs) != 2 {
+		t.Fatalf("expected 2 matched indexes, got %v", idxs)
+	}
+	if score <= 0 {
+		t.Errorf("word boundary match should score positively, got %d", score)
+	}
+}
+
+func TestFuzzyMatchNoMatch(t *testing.T) {
+	_, _, ok := fuzzyMatch("xyz123", "Quick Deploy")
+	if ok {
+		t.Error("expected no match for runes absent from target")
+	}
+}
+
+func TestFuzzyMatchOutOfOrderFails(t *testing.T) {
+	_, _, ok := fuzzyMatch("yolpeD kciuQ", "Quick Deploy")
+	if ok {
+		t.Error("runes must match in order")
+	}
+}
+
+func TestFuzzyMatchEmptyQueryMatchesEverything(t *testing.T) {
+	score, idxs, ok := fuzzyMatch("", "Quick Deploy")
+	if !ok || score != 0 || idxs != nil {
+		t.Errorf("empty query should trivially match, got score=%d idxs=%v ok=%v", score, idxs, ok)
+	}
+}
+
+func TestFuzzyMatchUnicode(t *testing.T) {
+	score, idxs, ok := fuzzyMatch("café", "Café Setup")
+	if !ok {
+		t.Fatal("expected unicode match to succeed")
+	}
+	if len(idxs) != 4 {
+		t.Errorf("expected 4 matched runes, got %d", len(idxs))
+	}
+	if score <= 0 {
+		t.Errorf("prefix unicode match should score positively, got %d", score)
+	}
+}
+
+func TestFuzzyMatchCaseInsensitive(t *testing.T) {
+	_, _, ok := fuzzyMatch("QUICK", "Quick Deploy")
+	if !ok {
+		t.Error("fuzzy match should be case-insensitive")
+	}
+}
+
+// ── searchState ─────────────────────────────────────────────────────────
+
+func TestNewSearchStateEmptyQueryListsAll(t *testing.T) {
+	cats := testCategories()
+	s := newSearchState(cats)
+	total := 0
+	for _, c := range cats {
+		total += len(c.commands)
+	}
+	if len(s.matches) != total {
+		t.Errorf("expected %d matches for empty query, got %d", total, len(s.matches))
+	}
+}
+
+func TestSearchStateRebuildRanksByScore(t *testing.T) {
+	s := newSearchState(testCategories())
+	s.query = "cmd"
+	s.rebuild()
+	for i := 1; i < len(s.matches); i++ {
+		if s.matches[i-1].score < s.matches[i].score {
+			t.Errorf("matches not sorted descending by score at index %d", i)
+		}
+	}
+}
+
+func TestSearchStateRebuildFiltersNonMatches(t *testing.T) {
+	s := newSearchState(testCategories())
+	s.query = "zzzznotfound"
+	s.rebuild()
+	if len(s.matches) != 0 {
+		t.Errorf("expected 0 matches for impossible query, got %d", len(s.matches))
+	}
+}
+
+// ── model integration ───────────────────────────────────────────────────
+
+func TestSlashOpensSearch(t *testing.T) {
+	m := testModel()
+	updated, _ := m.Update(keyMsg("/"))
+	m2 := updated.(model)
+	if m2.search == nil {
+		t.Fatal("'/' should open search mode")
+	}
+}
+
+func TestSearchEscCloses(t *testing.T) {
+	m := testModel()
+	m.search = newSearchState(m.categories)
+	updated, _ := m.Update(keyMsg("esc"))
+	m2 := updated.(model)
+	if m2.search != nil {
+		t.Error("esc should close search mode")
+	}
+}
+
+func TestSearchTypingUpdatesQuery(t *testing.T) {
+	m := testModel()
+	m.search = newSearchState(m.categories)
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	m2 := updated.(model)
+	if m2.search.query != "c" {
+		t.Errorf("expected query %q, got %q", "c", m2.search.query)
+	}
+}
+
+func TestSearchBackspaceEditsQuery(t *testing.T) {
+	m := testModel()
+	m.search = newSearchState(m.categories)
+	m.search.query = "cm"
+	m.search.rebuild()
+	updated, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	m2 := updated.(model)
+	if m2.search.query != "c" {
+		t.Errorf("backspace should shrink query to %q, got %q", "c", m2.search.query)
+	}
+}
+
+func TestSearchEnterActivatesCommand(t *testing.T) {
+	m := testModel()
+	m.search = newSearchState(m.categories)
+	m.search.query = "Cmd1"
+	m.search.rebuild()
+	updated, _ := m.Update(keyMsg("enter"))
+	m2 := updated.(model)
+	if m2.search != nil {
+		t.Error("enter should close search after activating a result")
+	}
+	if m2.flatIndex[m2.cursor].isCategory {
+		t.Error("cursor should land on the activated command")
+	}
+}
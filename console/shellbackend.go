@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ShellBackend abstracts over the different shells the console's scripts can
+// be run under, so streamScript doesn't need to hardcode a single `bash`
+// invocation. All of the console's scripts are bash scripts; a backend's job
+// is to get bash (or a wrapper around it) running with the right path
+// conventions for whatever shell is actually available on the host.
+type ShellBackend interface {
+	// Name identifies the backend, both for display and for matching against
+	// the OPENEMR_EKS_SHELL override.
+	Name() string
+
+	// Detect reports whether this backend's interpreter is available,
+	// using lookPath (exec.LookPath in production, faked in tests).
+	Detect(lookPath lookPathFunc) bool
+
+	// BuildCommand constructs the command to run script (with args) in
+	// workingDir, streaming under ctx the same way the former hardcoded
+	// `exec.CommandContext(ctx, "bash", ...)` call did.
+	BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd
+
+	// ConvertPath adapts a host filesystem path to whatever form this
+	// backend's interpreter expects it in (e.g. Git Bash's /c/... form).
+	ConvertPath(p string) string
+
+	// ResolveExitCode lets a backend correct the exit code exec.Cmd.Wait()
+	// reported for a just-finished run of script, in case the process's own
+	// exit status doesn't reliably reflect the script's real outcome.
+	// waitExitCode is passed through unchanged by every backend except
+	// powershellBackend, whose outer powershell.exe process can complete
+	// cleanly even when the bash command it shelled out to failed.
+	ResolveExitCode(script string, waitExitCode int) int
+}
+
+// shellBackends lists the backends detectShellBackend chooses from, in
+// priority order. Nushell sits last: it's never auto-detected (most hosts
+// that have it installed also have a POSIX shell available, and the console
+// shouldn't surprise a bash-capable user by routing through nu), so it's only
+// reachable via the OPENEMR_EKS_SHELL=nu override.
+var shellBackends = []ShellBackend{
+	gitBashBackend{},
+	wslBackend{},
+	powershellBackend{},
+	cmdBackend{},
+	unixShellBackend{},
+	zshBackend{},
+	fishBackend{},
+	nushellBackend{},
+}
+
+// detectShellBackend picks which ShellBackend runs the console's scripts.
+// OPENEMR_EKS_SHELL overrides the search entirely if set to one of bash, wsl,
+// pwsh, cmd, zsh, fish, or nu: it must name a known backend and that backend
+// must Detect, or detection fails outright rather than silently falling
+// back. Without an override, $SHELL's basename is tried next as a soft
+// preference (unlike the override, a $SHELL naming a backend that isn't
+// available just falls through rather than failing), and finally the first
+// available backend in shellBackends' priority order wins.
+func detectShellBackend(lookPath lookPathFunc) (ShellBackend, error) {
+	if override := shellBackendOverride(); override != "" {
+		known := false
+		for _, b := range shellBackends {
+			if b.Name() != override {
+				continue
+			}
+			known = true
+			if b.Detect(lookPath) {
+				return b, nil
+			}
+		}
+		if known {
+			return nil, fmt.Errorf("OPENEMR_EKS_SHELL=%s requested but %s is not available", override, override)
+		}
+		return nil, fmt.Errorf("OPENEMR_EKS_SHELL=%s is not a known shell backend (bash, wsl, pwsh, cmd, zsh, fish, nu)", override)
+	}
+	if preferred := preferredShellName(); preferred != "" {
+		for _, b := range shellBackends {
+			if b.Name() == preferred && b.Name() != "nu" && b.Detect(lookPath) {
+				return b, nil
+			}
+		}
+	}
+	for _, b := range shellBackends {
+		if b.Name() != "nu" && b.Detect(lookPath) {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("no supported shell backend found")
+}
+
+// preferredShellName reads $SHELL and returns the basename of the executable
+// it names (e.g. "/usr/bin/zsh" -> "zsh"), or "" if $SHELL is unset. This is
+// only a preference hint used by detectShellBackend when there's no explicit
+// OPENEMR_EKS_SHELL override: it lets a user's interactive shell of choice
+// (zsh, fish, ...) win over the bash/cmd/powershell defaults when it's
+// actually installed, without forcing a hard failure if it isn't.
+func preferredShellName() string {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		return ""
+	}
+	return filepath.Base(shell)
+}
+
+// shellBackendOverride reads OPENEMR_EKS_SHELL, the backend name the caller
+// wants detection forced to.
+func shellBackendOverride() string {
+	return os.Getenv("OPENEMR_EKS_SHELL")
+}
+
+// gitBashBackend runs scripts via Git for Windows' bundled bash, the
+// long-standing default on Windows hosts.
+type gitBashBackend struct{}
+
+func (gitBashBackend) Name() string { return "bash" }
+
+func (gitBashBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	_, err := lookPath("bash")
+	return err == nil
+}
+
+func (gitBashBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "bash", append([]string{gitBashBackend{}.ConvertPath(script)}, args...)...)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (gitBashBackend) ConvertPath(p string) string {
+	return convertWindowsPathToUnix(p)
+}
+
+func (gitBashBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+// wslBackend runs scripts inside Windows Subsystem for Linux via `wsl bash
+// <script> <args...>`, for hosts where WSL is set up but Git Bash isn't the
+// preferred shell.
+type wslBackend struct{}
+
+func (wslBackend) Name() string { return "wsl" }
+
+func (wslBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	_, err := lookPath("wsl")
+	return err == nil
+}
+
+func (wslBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	wslArgs := append([]string{"bash", wslBackend{}.ConvertPath(script)}, args...)
+	cmd := exec.CommandContext(ctx, "wsl", wslArgs...)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+// ConvertPath leaves the path as-is: WSL translates a Windows-style path
+// itself via wslpath when it sees one, so there's nothing for the console to
+// do here.
+func (wslBackend) ConvertPath(p string) string { return p }
+
+func (wslBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+// powershellBackend is the Windows backend tried after Git Bash and WSL (and
+// before cmdBackend): it shells out to
+// powershell.exe to invoke bash, for the rare host with neither Git Bash nor
+// WSL but still some bash on PATH reachable from a PowerShell session.
+//
+// Unlike the other backends, it doesn't hand the bash invocation to
+// powershell.exe directly via -Command: that outer process can exit 0 even
+// when the bash command it ran failed (Windows PowerShell 3/4 can silently
+// swallow a terminating error, and a failing WSL invocation behind it is
+// easy to miss entirely). Instead it generates an Execute-AndWriteOutput
+// wrapper script (see powershellwrapper.go) that runs the bash invocation
+// itself under $ErrorActionPreference = 'Stop' and records the real outcome
+// in a result file, which ResolveExitCode reads back after the process
+// exits.
+type powershellBackend struct{}
+
+func (powershellBackend) Name() string { return "pwsh" }
+
+func (powershellBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	_, err := lookPath("powershell")
+	return err == nil
+}
+
+func (powershellBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	mainScript := shellCommandLine(append([]string{"bash", script}, args...))
+	outputFile, logFile, resultFile := powerShellResultFiles(script)
+	wrapper := buildPowerShellWrapperScript(mainScript, outputFile, logFile, resultFile)
+
+	wrapperPath := filepath.Join(os.TempDir(), "openemr-eks-console", filepath.Base(resultFile)+".ps1")
+	_ = os.MkdirAll(filepath.Dir(wrapperPath), 0755)
+	_ = os.WriteFile(wrapperPath, []byte(wrapper), 0644)
+
+	// -NonInteractive and -OutputFormat Text keep PowerShell from ever
+	// blocking on a host prompt or emitting CLIXML-serialized objects (its
+	// default when stdout isn't a console) that would otherwise show up as
+	// binary noise in the TUI's log pane.
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-OutputFormat", "Text", "-ExecutionPolicy", "Bypass", "-File", wrapperPath)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (powershellBackend) ConvertPath(p string) string {
+	return convertWindowsPathToUnix(p)
+}
+
+// ResolveExitCode reads the result file the Execute-AndWriteOutput wrapper
+// wrote and returns the bash invocation's real exit code — distinguishing
+// "script exited non-zero" from "the launcher itself failed" (e.g. bash not
+// found) — falling back to waitExitCode (the outer powershell.exe process's
+// own exit code) if the wrapper never got far enough to write a result.
+func (powershellBackend) ResolveExitCode(script string, waitExitCode int) int {
+	return resolvePowerShellExitCode(script, waitExitCode)
+}
+
+// unixShellBackend runs scripts directly via bash, the backend used on macOS
+// and Linux where bash is already the native shell and no path translation
+// is needed.
+type unixShellBackend struct{}
+
+func (unixShellBackend) Name() string { return "bash" }
+
+func (unixShellBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := lookPath("bash")
+	return err == nil
+}
+
+func (unixShellBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "bash", append([]string{script}, args...)...)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (unixShellBackend) ConvertPath(p string) string { return p }
+
+func (unixShellBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+// nushellBackend wraps the bash invocation in `nu -c` so users on a
+// Nushell-first setup aren't forced through cmd.exe or a second shell just
+// to watch the script run; it's never auto-detected and only engages via
+// OPENEMR_EKS_SHELL=nu.
+type nushellBackend struct{}
+
+func (nushellBackend) Name() string { return "nu" }
+
+func (nushellBackend) Detect(lookPath lookPathFunc) bool {
+	_, err := lookPath("nu")
+	return err == nil
+}
+
+func (nushellBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	bashLine := shellCommandLine(append([]string{"bash", script}, args...))
+	nuScript := fmt.Sprintf(`%s; input "Press enter"`, bashLine)
+	cmd := exec.CommandContext(ctx, "nu", "-c", nuScript)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (nushellBackend) ConvertPath(p string) string { return p }
+
+func (nushellBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+// cmdBackend is the last Windows-native fallback: plain cmd.exe, present on
+// every Windows install, shelling out to bash (wrapped the same way
+// powershellBackend and nushellBackend wrap it) for hosts with a bash on
+// PATH but neither Git Bash's own launcher conventions, WSL, nor PowerShell
+// preferred.
+type cmdBackend struct{}
+
+func (cmdBackend) Name() string { return "cmd" }
+
+func (cmdBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	_, err := lookPath("bash")
+	return err == nil
+}
+
+func (cmdBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	inner := shellCommandLine(append([]string{"bash", script}, args...))
+	cmd := exec.CommandContext(ctx, "cmd", "/s", "/c", inner)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (cmdBackend) ConvertPath(p string) string {
+	return convertWindowsPathToUnix(p)
+}
+
+func (cmdBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+// zshBackend and fishBackend let a user whose $SHELL is zsh or fish (see
+// preferredShellName) keep their login shell's environment/rc files in play
+// around the script, while still running the script itself under bash
+// (console scripts are bash, not POSIX sh, so zsh/fish can't interpret them
+// directly) — the same wrap-and-delegate approach nushellBackend uses.
+type zshBackend struct{}
+
+func (zshBackend) Name() string { return "zsh" }
+
+func (zshBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := lookPath("zsh")
+	return err == nil
+}
+
+func (zshBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	bashLine := shellCommandLine(append([]string{"bash", script}, args...))
+	cmd := exec.CommandContext(ctx, "zsh", "-c", bashLine)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (zshBackend) ConvertPath(p string) string { return p }
+
+func (zshBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
+
+type fishBackend struct{}
+
+func (fishBackend) Name() string { return "fish" }
+
+func (fishBackend) Detect(lookPath lookPathFunc) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := lookPath("fish")
+	return err == nil
+}
+
+func (fishBackend) BuildCommand(ctx context.Context, script string, args []string, workingDir string) *exec.Cmd {
+	bashLine := shellCommandLine(append([]string{"bash", script}, args...))
+	cmd := exec.CommandContext(ctx, "fish", "-c", bashLine)
+	cmd.Dir = workingDir
+	return cmd
+}
+
+func (fishBackend) ConvertPath(p string) string { return p }
+
+func (fishBackend) ResolveExitCode(script string, waitExitCode int) int { return waitExitCode }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnixShellBackendDetectsOnNonWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixShellBackend is never selected on windows")
+	}
+	if !(unixShellBackend{}).Detect(fakeLookPath("bash")) {
+		t.Error("expected unixShellBackend to detect when bash is on PATH")
+	}
+	if (unixShellBackend{}).Detect(fakeLookPath()) {
+		t.Error("expected unixShellBackend not to detect without bash on PATH")
+	}
+}
+
+func TestWindowsOnlyBackendsNeverDetectOnNonWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this asserts the non-windows behavior")
+	}
+	found := fakeLookPath("bash", "wsl", "powershell")
+	if (gitBashBackend{}).Detect(found) {
+		t.Error("expected gitBashBackend not to detect on non-windows hosts")
+	}
+	if (wslBackend{}).Detect(found) {
+		t.Error("expected wslBackend not to detect on non-windows hosts")
+	}
+	if (powershellBackend{}).Detect(found) {
+		t.Error("expected powershellBackend not to detect on non-windows hosts")
+	}
+}
+
+func TestNushellBackendDetectsRegardlessOfPlatform(t *testing.T) {
+	if !(nushellBackend{}).Detect(fakeLookPath("nu")) {
+		t.Error("expected nushellBackend to detect when nu is on PATH")
+	}
+	if (nushellBackend{}).Detect(fakeLookPath()) {
+		t.Error("expected nushellBackend not to detect without nu on PATH")
+	}
+}
+
+func TestDetectShellBackendPicksFirstAvailableOnThisPlatform(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_SHELL", "")
+	backend, err := detectShellBackend(fakeLookPath("bash", "nu"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("priority order differs on windows; covered by the override tests instead")
+	}
+	if backend.Name() != "bash" {
+		t.Errorf("backend.Name() = %q, want bash", backend.Name())
+	}
+}
+
+func TestDetectShellBackendHonorsOverride(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_SHELL", "nu")
+	backend, err := detectShellBackend(fakeLookPath("bash", "nu"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backend.Name() != "nu" {
+		t.Errorf("backend.Name() = %q, want nu honoring OPENEMR_EKS_SHELL", backend.Name())
+	}
+}
+
+func TestDetectShellBackendOverrideNotAvailableFails(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_SHELL", "nu")
+	if _, err := detectShellBackend(fakeLookPath("bash")); err == nil {
+		t.Error("expected an error when the forced backend isn't available")
+	}
+}
+
+func TestDetectShellBackendOverrideUnknownNameFails(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_SHELL", "tcsh")
+	if _, err := detectShellBackend(fakeLookPath("bash")); err == nil {
+		t.Error("expected an error for an unrecognized OPENEMR_EKS_SHELL value")
+	}
+}
+
+func TestDetectShellBackendNoneAvailableFails(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_SHELL", "")
+	if _, err := detectShellBackend(fakeLookPath()); err == nil {
+		t.Error("expected an error when no backend is available")
+	}
+}
+
+func TestNushellBackendBuildCommandWrapsBashAndPrompts(t *testing.T) {
+	cmd := (nushellBackend{}).BuildCommand(context.Background(), "/tmp/deploy.sh", []string{"--yes"}, "/tmp")
+	if cmd.Path == "" {
+		t.Fatal("expected a resolved or literal command path")
+	}
+	joined := cmd.Args
+	if len(joined) < 3 || joined[1] != "-c" {
+		t.Fatalf("expected `nu -c <script>` invocation, got %v", joined)
+	}
+	if got := joined[2]; !containsAll(got, "bash", "/tmp/deploy.sh", "--yes", "input") {
+		t.Errorf("nu script %q missing expected pieces", got)
+	}
+}
+
+func TestCmdBackendNeverDetectsOnNonWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this asserts the non-windows behavior")
+	}
+	if (cmdBackend{}).Detect(fakeLookPath("bash")) {
+		t.Error("expected cmdBackend not to detect on non-windows hosts")
+	}
+}
+
+func TestCmdBackendBuildCommandWrapsBash(t *testing.T) {
+	cmd := (cmdBackend{}).BuildCommand(context.Background(), "/tmp/deploy.sh", []string{"--yes"}, "/tmp")
+	if len(cmd.Args) < 4 || cmd.Args[1] != "/s" || cmd.Args[2] != "/c" {
+		t.Fatalf("expected `cmd /s /c <script>` invocation, got %v", cmd.Args)
+	}
+	if got := cmd.Args[3]; !containsAll(got, "bash", "/tmp/deploy.sh", "--yes") {
+		t.Errorf("cmd script %q missing expected pieces", got)
+	}
+}
+
+func TestZshAndFishBackendsNeverDetectOnWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("this asserts the windows behavior")
+	}
+	found := fakeLookPath("zsh", "fish")
+	if (zshBackend{}).Detect(found) {
+		t.Error("expected zshBackend not to detect on windows hosts")
+	}
+	if (fishBackend{}).Detect(found) {
+		t.Error("expected fishBackend not to detect on windows hosts")
+	}
+}
+
+func TestZshBackendBuildCommandWrapsBash(t *testing.T) {
+	cmd := (zshBackend{}).BuildCommand(context.Background(), "/tmp/deploy.sh", []string{"--yes"}, "/tmp")
+	if len(cmd.Args) < 3 || cmd.Args[1] != "-c" {
+		t.Fatalf("expected `zsh -c <script>` invocation, got %v", cmd.Args)
+	}
+	if got := cmd.Args[2]; !containsAll(got, "bash", "/tmp/deploy.sh", "--yes") {
+		t.Errorf("zsh script %q missing expected pieces", got)
+	}
+}
+
+func TestFishBackendBuildCommandWrapsBash(t *testing.T) {
+	cmd := (fishBackend{}).BuildCommand(context.Background(), "/tmp/deploy.sh", []string{"--yes"}, "/tmp")
+	if len(cmd.Args) < 3 || cmd.Args[1] != "-c" {
+		t.Fatalf("expected `fish -c <script>` invocation, got %v", cmd.Args)
+	}
+	if got := cmd.Args[2]; !containsAll(got, "bash", "/tmp/deploy.sh", "--yes") {
+		t.Errorf("fish script %q missing expected pieces", got)
+	}
+}
+
+func TestPreferredShellNameReturnsBasename(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	if got := preferredShellName(); got != "zsh" {
+		t.Errorf("preferredShellName() = %q, want zsh", got)
+	}
+}
+
+func TestPreferredShellNameEmptyWhenUnset(t *testing.T) {
+	t.Setenv("SHELL", "")
+	if got := preferredShellName(); got != "" {
+		t.Errorf("preferredShellName() = %q, want empty", got)
+	}
+}
+
+func TestDetectShellBackendPrefersSHELLOverDefaultPriority(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this test exercises the unix shell preference")
+	}
+	t.Setenv("OPENEMR_EKS_SHELL", "")
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	backend, err := detectShellBackend(fakeLookPath("bash", "zsh"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backend.Name() != "zsh" {
+		t.Errorf("backend.Name() = %q, want zsh honoring $SHELL over the bash default", backend.Name())
+	}
+}
+
+func TestDetectShellBackendIgnoresSHELLWhenNotAvailable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this test exercises the unix shell preference")
+	}
+	t.Setenv("OPENEMR_EKS_SHELL", "")
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	backend, err := detectShellBackend(fakeLookPath("bash"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backend.Name() != "bash" {
+		t.Errorf("backend.Name() = %q, want bash since zsh isn't on PATH", backend.Name())
+	}
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
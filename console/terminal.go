@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// terminalEmulators lists the external terminal emulators detectTerminal
+// checks for, in priority order, along with how each one is invoked to run
+// a pre-built shell command line and keep the window open.
+// x-terminal-emulator leads the GUI emulators since it's Debian/Ubuntu's
+// own update-alternatives indirection to whatever the user already
+// configured as their default, so honoring it first defers to that choice
+// instead of second-guessing it. xterm is last since it's the least likely
+// to look native on any given desktop, and is only there as a universal
+// fallback.
+var terminalEmulators = []struct {
+	name string
+	args func(cmdline string) []string
+}{
+	{"x-terminal-emulator", func(c string) []string { return []string{"-e", "bash", "-c", c} }},
+	{"gnome-terminal", func(c string) []string { return []string{"--", "bash", "-c", c} }},
+	{"konsole", func(c string) []string { return []string{"-e", "bash", "-c", c} }},
+	{"xfce4-terminal", func(c string) []string { return []string{"-e", "bash -c " + shellQuote(c)} }},
+	{"alacritty", func(c string) []string { return []string{"-e", "bash", "-c", c} }},
+	{"wezterm", func(c string) []string { return []string{"start", "--", "bash", "-c", c} }},
+	{"kitty", func(c string) []string { return []string{"bash", "-c", c} }},
+	{"foot", func(c string) []string { return []string{"bash", "-c", c} }},
+	{"xterm", func(c string) []string { return []string{"-e", "bash", "-c", c} }},
+}
+
+// shellQuote wraps s in single quotes for safe inclusion in a shell command
+// line, escaping any single quotes it already contains.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommandLine joins argv into a single quoted shell command line, safe
+// to hand to `bash -c`.
+func shellCommandLine(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// hasDisplay reports whether a graphical session is available to launch a
+// terminal emulator into, per the X11 and Wayland environment variables.
+func hasDisplay() bool {
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// lookPathFunc matches exec.LookPath's signature, injected so detectTerminal
+// can be tested without depending on what's actually installed on $PATH.
+type lookPathFunc func(file string) (string, error)
+
+// detectTerminal picks which terminal emulator to launch a command into.
+// OPENEMR_EKS_TERMINAL overrides the search entirely if set: it must name
+// one of terminalEmulators (so its argv syntax is known) and be found on
+// $PATH, or detection fails outright rather than silently falling back.
+// Without that override, $TERMINAL (the desktop convention most GUI tools
+// already honor) is tried next as a soft preference the same way
+// preferredShellName lets $SHELL win over detectShellBackend's own
+// defaults: unlike OPENEMR_EKS_TERMINAL, a $TERMINAL naming an emulator
+// that isn't on PATH just falls through instead of failing outright.
+// Failing that, the first available emulator in terminalEmulators'
+// priority order wins. It returns ok=false if none can be found.
+func detectTerminal(lookPath lookPathFunc) (name string, ok bool) {
+	if override := os.Getenv("OPENEMR_EKS_TERMINAL"); override != "" {
+		for _, e := range terminalEmulators {
+			if e.name == override {
+				if _, err := lookPath(e.name); err == nil {
+					return e.name, true
+				}
+				return "", false
+			}
+		}
+		return "", false
+	}
+	if preferred := os.Getenv("TERMINAL"); preferred != "" {
+		for _, e := range terminalEmulators {
+			if e.name == preferred {
+				if _, err := lookPath(e.name); err == nil {
+					return e.name, true
+				}
+				break
+			}
+		}
+	}
+	for _, e := range terminalEmulators {
+		if _, err := lookPath(e.name); err == nil {
+			return e.name, true
+		}
+	}
+	return "", false
+}
+
+// terminalArgs returns the argv to pass to the emulator named name in order
+// to run cmdline in it, or nil if name isn't a known emulator.
+func terminalArgs(name, cmdline string) []string {
+	for _, e := range terminalEmulators {
+		if e.name == name {
+			return e.args(cmdline)
+		}
+	}
+	return nil
+}
+
+// terminalNames lists the emulators detectTerminal tries, for error messages.
+func terminalNames() string {
+	names := make([]string, len(terminalEmulators))
+	for i, e := range terminalEmulators {
+		names[i] = e.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// buildTerminalWrapperScript renders the bash source for a wrapper that cds
+// into workDir, runs argv, and reports its outcome before the window closes
+// — mirroring the pattern nushellBackend's `input "Press enter"` uses to
+// keep a spawned window
+// from vanishing the instant the script finishes: a terminal emulator exits
+// (closing its window) the moment the command it was handed returns, which
+// would otherwise make a script's exit code and final output impossible to
+// read.
+func buildTerminalWrapperScript(workDir string, argv []string) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "#!/bin/bash")
+	fmt.Fprintf(&b, "cd %s || exit 1\n", shellQuote(workDir))
+	fmt.Fprintf(&b, "%s\n", shellCommandLine(argv))
+	fmt.Fprintln(&b, `code=$?`)
+	fmt.Fprintln(&b, `echo`)
+	fmt.Fprintln(&b, `echo "Exit code: $code"`)
+	fmt.Fprintln(&b, `read -n 1 -s -r -p "Press any key to close..."`)
+	fmt.Fprintln(&b, `echo`)
+	fmt.Fprintln(&b, `exit "$code"`)
+	return b.String()
+}
+
+// writeTerminalWrapperScript writes buildTerminalWrapperScript's output to a
+// fresh, executable temp file and returns its path, so runInExternalTerminal
+// has a single file to hand the terminal emulator instead of composing an
+// ever-longer `bash -c` one-liner.
+func writeTerminalWrapperScript(workDir string, argv []string) (string, error) {
+	dir := filepath.Join(os.TempDir(), "openemr-eks-console")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, "term-wrapper-*.sh")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(buildTerminalWrapperScript(workDir, argv)); err != nil {
+		return "", err
+	}
+	if err := f.Chmod(0755); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// runInExternalTerminal launches cmd's script in a detached external
+// terminal window rather than streaming it inline, for scripts that need a
+// real interactive TTY (e.g. a sudo or MFA prompt) that a piped exec.Command
+// can't provide. It requires a graphical session (DISPLAY or
+// WAYLAND_DISPLAY) and a detectable emulator, and returns an error when
+// either is missing; it never falls back to running the script inline
+// itself.
+func runInExternalTerminal(cmd command, args []string) error {
+	if !hasDisplay() {
+		return fmt.Errorf("no graphical session (DISPLAY/WAYLAND_DISPLAY unset); run without --external-terminal instead")
+	}
+	name, ok := detectTerminal(exec.LookPath)
+	if !ok {
+		return fmt.Errorf("no supported terminal emulator found (tried %s; override with OPENEMR_EKS_TERMINAL)", terminalNames())
+	}
+
+	wrapperPath, err := writeTerminalWrapperScript(filepath.Dir(cmd.script), append([]string{"bash", cmd.script}, args...))
+	if err != nil {
+		return fmt.Errorf("failed to write terminal wrapper script: %w", err)
+	}
+
+	cmdline := shellCommandLine([]string{"bash", wrapperPath})
+	execCmd := exec.Command(name, terminalArgs(name, cmdline)...)
+	return execCmd.Start()
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeLookPath returns a lookPathFunc that reports found only for the names
+// in found.
+func fakeLookPath(found ...string) lookPathFunc {
+	set := make(map[string]bool, len(found))
+	for _, f := range found {
+		set[f] = true
+	}
+	return func(file string) (string, error) {
+		if set[file] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", fmt.Errorf("not found: %s", file)
+	}
+}
+
+func TestDetectTerminalPrefersHighestPriorityAvailable(t *testing.T) {
+	os.Unsetenv("OPENEMR_EKS_TERMINAL")
+	t.Setenv("TERMINAL", "")
+	name, ok := detectTerminal(fakeLookPath("xterm", "alacritty"))
+	if !ok || name != "alacritty" {
+		t.Errorf("detectTerminal() = (%q, %v), want (alacritty, true)", name, ok)
+	}
+}
+
+func TestDetectTerminalNoneAvailable(t *testing.T) {
+	os.Unsetenv("OPENEMR_EKS_TERMINAL")
+	t.Setenv("TERMINAL", "")
+	if _, ok := detectTerminal(fakeLookPath()); ok {
+		t.Error("expected detectTerminal to report false when nothing is on PATH")
+	}
+}
+
+func TestDetectTerminalHonorsOverride(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_TERMINAL", "xterm")
+	t.Setenv("TERMINAL", "")
+	name, ok := detectTerminal(fakeLookPath("gnome-terminal", "xterm"))
+	if !ok || name != "xterm" {
+		t.Errorf("detectTerminal() = (%q, %v), want (xterm, true) honoring the override over gnome-terminal's priority", name, ok)
+	}
+}
+
+func TestDetectTerminalOverrideNotOnPathFails(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_TERMINAL", "kitty")
+	t.Setenv("TERMINAL", "")
+	if _, ok := detectTerminal(fakeLookPath("gnome-terminal")); ok {
+		t.Error("expected an override naming an emulator not on PATH to fail outright, not fall back")
+	}
+}
+
+func TestDetectTerminalOverrideUnknownNameFails(t *testing.T) {
+	t.Setenv("OPENEMR_EKS_TERMINAL", "not-a-real-terminal")
+	t.Setenv("TERMINAL", "")
+	if _, ok := detectTerminal(fakeLookPath("xterm")); ok {
+		t.Error("expected an unrecognized override name to fail rather than silently ignoring it")
+	}
+}
+
+func TestDetectTerminalHonorsTerminalEnvVar(t *testing.T) {
+	os.Unsetenv("OPENEMR_EKS_TERMINAL")
+	t.Setenv("TERMINAL", "kitty")
+	name, ok := detectTerminal(fakeLookPath("gnome-terminal", "kitty"))
+	if !ok || name != "kitty" {
+		t.Errorf("detectTerminal() = (%q, %v), want (kitty, true) honoring $TERMINAL over gnome-terminal's priority", name, ok)
+	}
+}
+
+func TestDetectTerminalFallsThroughWhenTerminalEnvVarNotOnPath(t *testing.T) {
+	os.Unsetenv("OPENEMR_EKS_TERMINAL")
+	t.Setenv("TERMINAL", "kitty")
+	name, ok := detectTerminal(fakeLookPath("gnome-terminal"))
+	if !ok || name != "gnome-terminal" {
+		t.Errorf("detectTerminal() = (%q, %v), want (gnome-terminal, true) falling through a $TERMINAL that isn't installed", name, ok)
+	}
+}
+
+func TestTerminalArgsUnknownEmulatorReturnsNil(t *testing.T) {
+	if args := terminalArgs("not-a-real-terminal", "bash -c true"); args != nil {
+		t.Errorf("expected nil args for an unknown emulator, got %v", args)
+	}
+}
+
+func TestTerminalArgsGnomeTerminalUsesDoubleDash(t *testing.T) {
+	args := terminalArgs("gnome-terminal", "echo hi")
+	want := []string{"--", "bash", "-c", "echo hi"}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("args[%d] = %q, want %q", i, args[i], want[i])
+		}
+	}
+}
+
+func TestShellCommandLineQuotesArgumentsWithSpaces(t *testing.T) {
+	line := shellCommandLine([]string{"bash", "/tmp/my script.sh", "snap 1"})
+	want := `'bash' '/tmp/my script.sh' 'snap 1'`
+	if line != want {
+		t.Errorf("shellCommandLine = %q, want %q", line, want)
+	}
+}
+
+func TestShellQuoteEscapesEmbeddedSingleQuotes(t *testing.T) {
+	got := shellQuote("it's")
+	want := `'it'\''s'`
+	if got != want {
+		t.Errorf("shellQuote(%q) = %q, want %q", "it's", got, want)
+	}
+}
+
+func TestHasDisplayChecksX11AndWayland(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if hasDisplay() {
+		t.Error("expected hasDisplay to be false with neither var set")
+	}
+	t.Setenv("WAYLAND_DISPLAY", ":0")
+	if !hasDisplay() {
+		t.Error("expected hasDisplay to be true with WAYLAND_DISPLAY set")
+	}
+}
+
+func TestBuildTerminalWrapperScriptIncludesWorkDirAndCommand(t *testing.T) {
+	script := buildTerminalWrapperScript("/home/user/project/scripts", []string{"bash", "deploy.sh", "--yes"})
+	if !strings.HasPrefix(script, "#!/bin/bash\n") {
+		t.Errorf("expected a bash shebang, got %q", script)
+	}
+	if !strings.Contains(script, "cd "+shellQuote("/home/user/project/scripts")) {
+		t.Errorf("expected the wrapper to cd into the script's directory, got %q", script)
+	}
+	if !strings.Contains(script, shellCommandLine([]string{"bash", "deploy.sh", "--yes"})) {
+		t.Errorf("expected the wrapper to run the given command, got %q", script)
+	}
+	if !strings.Contains(script, "Press any key to close") {
+		t.Errorf("expected the wrapper to wait for a keypress before the window closes, got %q", script)
+	}
+	if !strings.Contains(script, `exit "$code"`) {
+		t.Errorf("expected the wrapper to exit with the wrapped command's own exit code, got %q", script)
+	}
+}
+
+func TestWriteTerminalWrapperScriptIsExecutable(t *testing.T) {
+	path, err := writeTerminalWrapperScript(t.TempDir(), []string{"bash", "true"})
+	if err != nil {
+		t.Fatalf("writeTerminalWrapperScript failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("wrapper script wasn't written: %v", err)
+	}
+	if info.Mode()&0100 == 0 {
+		t.Errorf("expected wrapper script to be executable, mode = %v", info.Mode())
+	}
+}
+
+func TestRunInExternalTerminalFailsWithoutDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if err := runInExternalTerminal(command{script: "/tmp/a.sh"}, nil); err == nil {
+		t.Error("expected an error when no graphical session is available")
+	}
+}
+
+func TestStripExternalTerminalFlag(t *testing.T) {
+	remaining, external := stripExternalTerminalFlag([]string{"--bucket", "my-bucket", "--external-terminal"})
+	if !external {
+		t.Error("expected --external-terminal to be detected")
+	}
+	want := []string{"--bucket", "my-bucket"}
+	if len(remaining) != len(want) {
+		t.Fatalf("remaining = %v, want %v", remaining, want)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("remaining[%d] = %q, want %q", i, remaining[i], want[i])
+		}
+	}
+}
+
+func TestStripExternalTerminalFlagAbsent(t *testing.T) {
+	remaining, external := stripExternalTerminalFlag([]string{"--bucket", "my-bucket"})
+	if external {
+		t.Error("expected --external-terminal to be absent")
+	}
+	if len(remaining) != 2 {
+		t.Errorf("remaining = %v, want unchanged args", remaining)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// transcriptLogDir returns ~/.openemr-eks-console/logs, creating it if it
+// doesn't exist yet.
+func transcriptLogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".openemr-eks-console", "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// saveTranscript writes lines (a run's full captured output) to
+// ~/.openemr-eks-console/logs/<timestamp>-<command>.log and returns the path
+// it wrote, so a user who hit a scrollback limit or wants to attach a run's
+// output to a ticket doesn't have to re-run the command under `tee`.
+func saveTranscript(title string, lines []logLine, at time.Time) (string, error) {
+	dir, err := transcriptLogDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%s.log", at.Format("20060102-150405"), slugify(title))
+	path := filepath.Join(dir, name)
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text)
+		b.WriteString("\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveTranscriptWritesJoinedLines(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	lines := []logLine{
+		{stream: "stdout", text: "hello"},
+		{stream: "stderr", text: "uh oh"},
+	}
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	path, err := saveTranscript("Quick Deploy", lines, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantName := "20260102-150405-quick-deploy.log"
+	if filepath.Base(path) != wantName {
+		t.Errorf("path = %q, want basename %q", path, wantName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading saved transcript: %v", err)
+	}
+	if got := string(data); got != "hello\nuh oh\n" {
+		t.Errorf("transcript contents = %q, want %q", got, "hello\nuh oh\n")
+	}
+}
+
+func TestSaveTranscriptCreatesLogDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := saveTranscript("Cmd", nil, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".openemr-eks-console", "logs")); err != nil {
+		t.Errorf("expected the logs directory to be created: %v", err)
+	}
+}
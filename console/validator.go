@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fieldValidator checks a prompt's value beyond the basic "required" check:
+// a regex pattern, a fixed set of choices (rendered as a select), a
+// numeric range, or a filesystem existence check. A nil validator always
+// passes, so fields that don't need one need no special-casing at call
+// sites.
+type fieldValidator struct {
+	kind     string // "regex", "enum", "range", or "path"
+	pattern  *regexp.Regexp
+	choices  []string
+	min, max float64
+	pathKind string // "file", "dir", or "writable", for kind == "path"
+}
+
+// newRegexValidator compiles pattern into a validator that requires the
+// field's value to match it.
+func newRegexValidator(pattern string) (*fieldValidator, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return &fieldValidator{kind: "regex", pattern: re}, nil
+}
+
+// newEnumValidator builds a validator that restricts the field to one of
+// choices, rendered as a cyclable select rather than free text.
+func newEnumValidator(choices []string) *fieldValidator {
+	return &fieldValidator{kind: "enum", choices: choices}
+}
+
+// newRangeValidator builds a validator that requires the field's value to
+// parse as a number within [min, max].
+func newRangeValidator(min, max float64) *fieldValidator {
+	return &fieldValidator{kind: "range", min: min, max: max}
+}
+
+// newPathValidator builds a validator that requires the field's value to be
+// an existing path, optionally narrowed to a file, a directory, or one this
+// process can write to.
+func newPathValidator(pathKind string) *fieldValidator {
+	return &fieldValidator{kind: "path", pathKind: pathKind}
+}
+
+// parseRangeSpec parses a "min:max" range specification, as used in
+// commands.yaml's prompt.validate field for type: range.
+func parseRangeSpec(spec string) (min, max float64, err error) {
+	lo, hi, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("range spec %q must be \"min:max\"", spec)
+	}
+	min, err = strconv.ParseFloat(strings.TrimSpace(lo), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("range spec %q: invalid min: %w", spec, err)
+	}
+	max, err = strconv.ParseFloat(strings.TrimSpace(hi), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("range spec %q: invalid max: %w", spec, err)
+	}
+	return min, max, nil
+}
+
+// validate reports whether value satisfies v, returning a human-readable
+// reason on failure. Called only for non-empty values; whether an empty
+// value is acceptable is governed separately by inputField.required.
+func (v *fieldValidator) validate(value string) (ok bool, reason string) {
+	if v == nil {
+		return true, ""
+	}
+	switch v.kind {
+	case "regex":
+		if !v.pattern.MatchString(value) {
+			return false, fmt.Sprintf("must match %s", v.pattern.String())
+		}
+	case "enum":
+		for _, c := range v.choices {
+			if value == c {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("must be one of: %s", strings.Join(v.choices, ", "))
+	case "range":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, "must be a number"
+		}
+		if n < v.min || n > v.max {
+			return false, fmt.Sprintf("must be between %g and %g", v.min, v.max)
+		}
+	case "path":
+		info, err := os.Stat(value)
+		if err != nil {
+			return false, "path does not exist"
+		}
+		switch v.pathKind {
+		case "dir":
+			if !info.IsDir() {
+				return false, "must be a directory"
+			}
+		case "file":
+			if info.IsDir() {
+				return false, "must be a file"
+			}
+		case "writable":
+			// Best-effort permission-bit check rather than a real access(2)
+			// syscall, since that requires matching the running uid/gid
+			// against the file's owner, which isn't worth the complexity here.
+			if info.Mode().Perm()&0200 == 0 {
+				return false, "is not writable"
+			}
+		}
+	}
+	return true, ""
+}
+
+// cycleChoice returns the choice after current in choices (or, with
+// delta -1, before it), wrapping around. An unrecognized current value
+// starts from the first choice.
+func cycleChoice(choices []string, current string, delta int) string {
+	idx := 0
+	for i, c := range choices {
+		if c == current {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + delta + len(choices)) % len(choices)
+	return choices[idx]
+}
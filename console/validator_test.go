@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRegexValidator(t *testing.T) {
+	v, err := newRegexValidator(`^us-[a-z]+-\d$`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := v.validate("us-east-1"); !ok {
+		t.Error("expected a matching region code to pass")
+	}
+	if ok, reason := v.validate("not-a-region"); ok || reason == "" {
+		t.Error("expected a non-matching value to fail with a reason")
+	}
+}
+
+func TestRegexValidatorRejectsInvalidPattern(t *testing.T) {
+	if _, err := newRegexValidator("["); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestEnumValidator(t *testing.T) {
+	v := newEnumValidator([]string{"7.0", "7.0.1", "7.0.2"})
+	if ok, _ := v.validate("7.0.1"); !ok {
+		t.Error("expected a listed choice to pass")
+	}
+	if ok, reason := v.validate("6.9"); ok || reason == "" {
+		t.Error("expected an unlisted choice to fail with a reason")
+	}
+}
+
+func TestRangeValidator(t *testing.T) {
+	v := newRangeValidator(1, 100)
+	if ok, _ := v.validate("50"); !ok {
+		t.Error("expected an in-range value to pass")
+	}
+	if ok, _ := v.validate("101"); ok {
+		t.Error("expected an out-of-range value to fail")
+	}
+	if ok, reason := v.validate("not-a-number"); ok || reason == "" {
+		t.Error("expected a non-numeric value to fail with a reason")
+	}
+}
+
+func TestParseRangeSpec(t *testing.T) {
+	min, max, err := parseRangeSpec("1:100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if min != 1 || max != 100 {
+		t.Errorf("min=%v max=%v, want 1 and 100", min, max)
+	}
+	if _, _, err := parseRangeSpec("bad"); err == nil {
+		t.Error("expected an error for a malformed range spec")
+	}
+}
+
+func TestPathValidatorFileExists(t *testing.T) {
+	v := newPathValidator("file")
+	dir := t.TempDir()
+	if ok, reason := v.validate(dir); ok {
+		t.Errorf("expected a directory to fail a file check, got ok with reason %q", reason)
+	}
+
+	file := dir + "/exists.txt"
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+	if ok, _ := v.validate(file); !ok {
+		t.Error("expected an existing file to pass")
+	}
+	if ok, _ := v.validate(dir + "/missing.txt"); ok {
+		t.Error("expected a missing path to fail")
+	}
+}
+
+func TestPathValidatorDir(t *testing.T) {
+	v := newPathValidator("dir")
+	dir := t.TempDir()
+	if ok, _ := v.validate(dir); !ok {
+		t.Error("expected an existing directory to pass")
+	}
+
+	file := dir + "/exists.txt"
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+	if ok, _ := v.validate(file); ok {
+		t.Error("expected a file to fail a directory check")
+	}
+}
+
+func TestCycleChoice(t *testing.T) {
+	choices := []string{"a", "b", "c"}
+	if got := cycleChoice(choices, "a", 1); got != "b" {
+		t.Errorf("cycleChoice forward from a = %q, want b", got)
+	}
+	if got := cycleChoice(choices, "a", -1); got != "c" {
+		t.Errorf("cycleChoice backward from a should wrap to c, got %q", got)
+	}
+	if got := cycleChoice(choices, "unknown", 1); got != "b" {
+		t.Errorf("cycleChoice from an unrecognized value should start from the first choice, got %q", got)
+	}
+}
+
+func TestNilValidatorAlwaysPasses(t *testing.T) {
+	var v *fieldValidator
+	if ok, reason := v.validate("anything"); !ok || reason != "" {
+		t.Errorf("expected a nil validator to always pass, got ok=%v reason=%q", ok, reason)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// enableVirtualTerminalProcessing is a no-op outside Windows: every other
+// supported terminal (plus Windows Terminal itself) already interprets ANSI
+// escape codes natively.
+func enableVirtualTerminalProcessing() {}
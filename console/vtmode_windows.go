@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// stdOutputHandle is STD_OUTPUT_HANDLE (-11), expressed the way the
+// syscall's DWORD argument expects a negative handle index: NOT(x) == -x-1,
+// so ^uintptr(10) == -11.
+const stdOutputHandle = ^uintptr(10)
+
+// enableVirtualTerminalProcessingFlag is ENABLE_VIRTUAL_TERMINAL_PROCESSING.
+const enableVirtualTerminalProcessingFlag = 0x0004
+
+// enableVirtualTerminalProcessing turns on ANSI escape sequence interpretation
+// on the native Windows console by setting ENABLE_VIRTUAL_TERMINAL_PROCESSING
+// on stdout's console mode. Windows Terminal already defaults this on, but
+// plain conhost on Windows 10 doesn't, so without this
+// the ansi package's styled output would show up as literal escape codes
+// instead of colors there. Any failure (stdout isn't a console, the calls
+// aren't available) is silently ignored, the same as usePTY's Windows
+// fallback: a console that can't be upgraded should still run, just
+// uncolored.
+func enableVirtualTerminalProcessing() {
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	getStdHandle := kernel32.NewProc("GetStdHandle")
+	getConsoleMode := kernel32.NewProc("GetConsoleMode")
+	setConsoleMode := kernel32.NewProc("SetConsoleMode")
+
+	handle, _, _ := getStdHandle.Call(stdOutputHandle)
+	if handle == 0 || handle == uintptr(syscall.InvalidHandle) {
+		return
+	}
+
+	var mode uint32
+	if ret, _, _ := getConsoleMode.Call(handle, uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return
+	}
+
+	setConsoleMode.Call(handle, uintptr(mode|enableVirtualTerminalProcessingFlag))
+}
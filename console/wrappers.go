@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cliInstallWrappers implements `openemr-eks install-wrappers --bin-dir <dir>`:
+// it writes one native launcher per catalog command into dir, named
+// "openemr-<category-slug>-<title-slug>.cmd", so a user can run it without
+// going through the TUI or remembering the run subcommand's slug syntax:
+// `cmd /c openemr-deployment-quick-deploy.cmd --bucket my-bucket` on
+// Windows, `bash openemr-deployment-quick-deploy.cmd --bucket my-bucket`
+// elsewhere. This mirrors how TeX Live stamps one generic wrapper template
+// per installed script rather than building a bespoke binary for each.
+func cliInstallWrappers(args []string) int {
+	binDir := ""
+	for i, a := range args {
+		if a == "--bin-dir" && i+1 < len(args) {
+			binDir = args[i+1]
+		}
+	}
+	if binDir == "" {
+		fmt.Fprintln(os.Stderr, "usage: openemr-eks install-wrappers --bin-dir <dir>")
+		return 1
+	}
+
+	root := resolveProjectRoot()
+	cats := loadCategories(root, scriptsDir(root))
+
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create %s: %s\n", binDir, err)
+		return 1
+	}
+
+	count := 0
+	for _, cat := range cats {
+		if cat.name == recentCategoryName {
+			continue
+		}
+		for _, cmd := range cat.commands {
+			name := "openemr-" + slugify(cat.name) + "-" + slugify(cmd.title)
+			path, content := wrapperFor(root, name, cmd.script, binDir)
+			if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", path, err)
+				return 1
+			}
+			count++
+		}
+	}
+
+	fmt.Printf("installed %d wrapper(s) into %s\n", count, binDir)
+	fmt.Println(`run one with "cmd /c <name>.cmd ..." on Windows, or "bash <name>.cmd ..." elsewhere`)
+	return 0
+}
+
+// wrapperFor returns the filename and contents of the launcher for script
+// (an absolute path under root): a single ".cmd" file, generated by
+// multishellWrapper, that's valid as both a Windows batch file and a POSIX
+// shell script — see multishellWrapper's comment for how. This replaced a
+// unixWrapper/windowsWrapper pair of near-identical generators that existed
+// purely to embed the same root/relScript pair in two different syntaxes
+// and had to be kept in sync by hand.
+func wrapperFor(root, name, script, binDir string) (path string, content string) {
+	rel, err := filepath.Rel(root, script)
+	if err != nil {
+		rel = script
+	}
+	rel = filepath.ToSlash(rel)
+
+	return filepath.Join(binDir, name+".cmd"), multishellWrapper(root, rel)
+}
+
+// multishellWrapper renders one wrapper file that is simultaneously a valid
+// POSIX shell script and a valid Windows batch file, the way punkshell's
+// scriptwrap does it: the first line is a no-op to cmd.exe but a live
+// command to sh, so the two interpreters diverge right there instead of
+// needing separate files.
+//
+//	:;OPENEMR_EKS_PROJECT_ROOT=${OPENEMR_EKS_PROJECT_ROOT:-'root'}; exec bash "$OPENEMR_EKS_PROJECT_ROOT/rel" "$@"
+//	@echo off
+//	...
+//
+// To sh, that first line is three commands joined by `;`: the bare `:`
+// builtin (a no-op), an assignment that defaults OPENEMR_EKS_PROJECT_ROOT to
+// the embedded root, and the `exec`,
+// which replaces the shell with bash right there — the batch lines below
+// are never read. To cmd.exe, any line starting with `:` is a label
+// declaration that runs to end of line, so the entire line — `;` and all —
+// is swallowed as the label's name and skipped, falling through to the
+// `@echo off` batch body beneath it. Because this requires an explicit
+// `bash` or `cmd /c` on the front (a bare PATH lookup won't find a shebang
+// here), cliInstallWrappers documents the resulting invocation per OS
+// rather than promising bare-word execution.
+func multishellWrapper(root, relScript string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":;OPENEMR_EKS_PROJECT_ROOT=${OPENEMR_EKS_PROJECT_ROOT:-%s}; exec bash \"$OPENEMR_EKS_PROJECT_ROOT/%s\" \"$@\"\n",
+		shellQuote(filepath.ToSlash(root)), relScript)
+	fmt.Fprintln(&b, "@echo off")
+	fmt.Fprintf(&b, `if "%%OPENEMR_EKS_PROJECT_ROOT%%"=="" set OPENEMR_EKS_PROJECT_ROOT=%s`+"\n", root)
+	fmt.Fprintf(&b, `bash "%%OPENEMR_EKS_PROJECT_ROOT%%\%s" %%*`+"\n", strings.ReplaceAll(relScript, "/", "\\"))
+	// Plain WriteString, not Fprint: go vet's printf analyzer flags the
+	// literal "%E" in "%ERRORLEVEL%" as a bad format directive even though
+	// no format string is in play, and Fprint doesn't dodge that check.
+	b.WriteString("exit /b %ERRORLEVEL%\n")
+	return b.String()
+}
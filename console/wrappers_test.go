@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultishellWrapperEmbedsRootAndHonorsOverride(t *testing.T) {
+	out := multishellWrapper("/opt/openemr-on-eks", "scripts/quick-deploy.sh")
+	if !strings.HasPrefix(out, ":;") {
+		t.Errorf("expected the wrapper to open with the `:;` sh/batch hinge line, got %q", out)
+	}
+	if !strings.Contains(out, "OPENEMR_EKS_PROJECT_ROOT:-") || !strings.Contains(out, "/opt/openemr-on-eks") {
+		t.Errorf("expected the resolved root embedded as a default, got %q", out)
+	}
+	if !strings.Contains(out, `exec bash "$OPENEMR_EKS_PROJECT_ROOT/scripts/quick-deploy.sh" "$@"`) {
+		t.Errorf("expected the wrapper to exec the script with args forwarded under sh, got %q", out)
+	}
+	if !strings.Contains(out, "@echo off") {
+		t.Error("expected a @echo off batch header")
+	}
+	if !strings.Contains(out, `set OPENEMR_EKS_PROJECT_ROOT=/opt/openemr-on-eks`) {
+		t.Errorf("expected the resolved root embedded as a batch default, got %q", out)
+	}
+	if !strings.Contains(out, `bash "%OPENEMR_EKS_PROJECT_ROOT%\scripts\quick-deploy.sh" %*`) {
+		t.Errorf("expected the wrapper to invoke bash with args forwarded under batch, got %q", out)
+	}
+}
+
+func TestWrapperForUsesRelativeScriptPathAndCmdExtension(t *testing.T) {
+	path, content := wrapperFor("/opt/openemr-on-eks", "openemr-deployment-quick-deploy", "/opt/openemr-on-eks/scripts/quick-deploy.sh", "/usr/local/bin")
+	if filepath.Ext(path) != ".cmd" {
+		t.Errorf("expected a .cmd wrapper on every platform, got %q", path)
+	}
+	if !strings.Contains(content, "scripts/quick-deploy.sh") {
+		t.Errorf("expected a relative script path in the wrapper, got %q", content)
+	}
+}
+
+// TestMultishellWrapperRunsUnderBash actually executes the generated
+// wrapper under bash to confirm the sh half of the polyglot trick (the
+// `:;...; exec bash ...` hinge line) really does exec the target script
+// and propagate its exit code, rather than just eyeballing the string.
+func TestMultishellWrapperRunsUnderBash(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "scripts"), 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	scriptPath := filepath.Join(root, "scripts", "quick-deploy.sh")
+	if err := os.WriteFile(scriptPath, []byte("#!/bin/bash\necho \"ran: $*\"\nexit 7\n"), 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	wrapperPath := filepath.Join(root, "wrapper.cmd")
+	if err := os.WriteFile(wrapperPath, []byte(multishellWrapper(root, "scripts/quick-deploy.sh")), 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	cmd := exec.Command("bash", wrapperPath, "--bucket", "my-bucket")
+	out, err := cmd.CombinedOutput()
+	if !strings.Contains(string(out), "ran: --bucket my-bucket") {
+		t.Errorf("expected the wrapped script's output, got %q", out)
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 7 {
+		t.Errorf("expected exit code 7 propagated from the wrapped script, got err=%v", err)
+	}
+}